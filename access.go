@@ -0,0 +1,389 @@
+package statistica
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrPermissionDenied returns when principal has no access to dimension or metric.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Principal this struct represents subject of access policy.
+type Principal struct {
+	// ID contains identifier of principal.
+	ID string
+
+	// Roles contains list of principal roles.
+	Roles []string
+}
+
+// HasRole returns true if principal has role.
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+
+	for i := range p.Roles {
+		if p.Roles[i] == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal returns copy of context which carries principal.
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns principal stored by ContextWithPrincipal.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+
+	return principal, ok && principal != nil
+}
+
+// AccessPolicy common interface of access rules.
+type AccessPolicy interface {
+	// AllowDimension returns true if principal has access to dimension.
+	AllowDimension(principal *Principal, key DimensionKey) bool
+	// AllowMetric returns true if principal has access to metric.
+	AllowMetric(principal *Principal, name string) bool
+}
+
+// RoleAccessPolicy role based implementation of AccessPolicy.
+// Dimensions and metrics which are not listed are allowed for everyone.
+type RoleAccessPolicy struct {
+	// Dimensions contains list of roles allowed to see dimension.
+	Dimensions map[DimensionKey][]string
+
+	// Metrics contains list of roles allowed to see metric.
+	Metrics map[string][]string
+}
+
+// AllowDimension returns true if principal has access to dimension.
+func (p *RoleAccessPolicy) AllowDimension(principal *Principal, key DimensionKey) bool {
+	roles, ok := p.Dimensions[key]
+	if !ok {
+		return true
+	}
+
+	return hasAnyRole(principal, roles)
+}
+
+// AllowMetric returns true if principal has access to metric.
+func (p *RoleAccessPolicy) AllowMetric(principal *Principal, name string) bool {
+	roles, ok := p.Metrics[name]
+	if !ok {
+		return true
+	}
+
+	return hasAnyRole(principal, roles)
+}
+
+func hasAnyRole(principal *Principal, roles []string) bool {
+	for i := range roles {
+		if principal.HasRole(roles[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PermissionError this struct describes rejected field of request.
+type PermissionError struct {
+	// Kind contains kind of field: dimension or metric.
+	Kind string
+
+	// Field contains name of field.
+	Field string
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("%s: %s %q", ErrPermissionDenied, e.Kind, e.Field)
+}
+
+func (e *PermissionError) Unwrap() error {
+	return ErrPermissionDenied
+}
+
+// AccessRepository implementation of ReadRepository which checks access policy of principal.
+type AccessRepository struct {
+	repository ReadRepository
+	policy     AccessPolicy
+	principal  *Principal
+}
+
+// NewAccessRepository returns new instance of AccessRepository.
+func NewAccessRepository(repository ReadRepository, policy AccessPolicy, principal *Principal) *AccessRepository {
+	return &AccessRepository{
+		repository: repository,
+		policy:     policy,
+		principal:  principal,
+	}
+}
+
+// Total returns total rows by query ItemsRequest.
+func (r *AccessRepository) Total(req *ItemsRequest) (uint64, error) {
+	return r.TotalContext(context.Background(), req)
+}
+
+// TotalContext returns total rows by query ItemsRequest.
+func (r *AccessRepository) TotalContext(ctx context.Context, req *ItemsRequest) (uint64, error) {
+	if err := r.checkRequest(req); err != nil {
+		return 0, err
+	}
+
+	return r.repository.TotalContext(ctx, req)
+}
+
+// Values returns values ValueResponse by query ItemsRequest.
+func (r *AccessRepository) Values(req *ItemsRequest) ([]*ValueResponse, error) {
+	return r.ValuesContext(context.Background(), req)
+}
+
+// ValuesContext returns values ValueResponse by query ItemsRequest.
+func (r *AccessRepository) ValuesContext(ctx context.Context, req *ItemsRequest) ([]*ValueResponse, error) {
+	if err := r.checkRequest(req); err != nil {
+		return nil, err
+	}
+
+	return r.repository.ValuesContext(ctx, req)
+}
+
+// Grouped returns rows by query ItemsRequest without metrics hidden from principal.
+func (r *AccessRepository) Grouped(req *ItemsRequest) ([]*ItemRow, error) {
+	return r.GroupedContext(context.Background(), req)
+}
+
+// GroupedContext returns rows by query ItemsRequest without metrics hidden from principal.
+func (r *AccessRepository) GroupedContext(ctx context.Context, req *ItemsRequest) ([]*ItemRow, error) {
+	if err := r.checkRequest(req); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.repository.GroupedContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		r.hideMetrics(rows[i])
+	}
+
+	return rows, nil
+}
+
+// Page returns rows, total and summary by query ItemsRequest without metrics hidden from principal.
+func (r *AccessRepository) Page(req *ItemsRequest) (*ItemsResponse, error) {
+	return r.PageContext(context.Background(), req)
+}
+
+// PageContext returns rows, total and summary by query ItemsRequest without metrics hidden from principal.
+func (r *AccessRepository) PageContext(ctx context.Context, req *ItemsRequest) (*ItemsResponse, error) {
+	if err := r.checkRequest(req); err != nil {
+		return nil, err
+	}
+
+	response, err := r.repository.PageContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range response.Rows {
+		r.hideMetrics(response.Rows[i])
+	}
+
+	if response.Summary != nil {
+		r.hideMetrics(response.Summary)
+	}
+
+	return response, nil
+}
+
+// ValuesEach calls fn for each value ValueResponse by query ItemsRequest.
+func (r *AccessRepository) ValuesEach(ctx context.Context, req *ItemsRequest, fn func(value *ValueResponse) error) error {
+	if err := r.checkRequest(req); err != nil {
+		return err
+	}
+
+	return valuesEach(ctx, r.repository, req, fn)
+}
+
+// GroupedEach calls fn for each row by query ItemsRequest without metrics hidden from principal.
+func (r *AccessRepository) GroupedEach(ctx context.Context, req *ItemsRequest, fn func(row *ItemRow) error) error {
+	if err := r.checkRequest(req); err != nil {
+		return err
+	}
+
+	return groupedEach(ctx, r.repository, req, func(row *ItemRow) error {
+		r.hideMetrics(row)
+
+		return fn(row)
+	})
+}
+
+func (r *AccessRepository) hideMetrics(row *ItemRow) {
+	for name := range row.Metrics {
+		if !r.policy.AllowMetric(r.principal, name) {
+			delete(row.Metrics, name)
+		}
+	}
+
+	for name := range row.ExactMetrics {
+		if !r.policy.AllowMetric(r.principal, name) {
+			delete(row.ExactMetrics, name)
+		}
+	}
+}
+
+// Metrics returns metrics allowed for principal.
+func (r *AccessRepository) Metrics() ([]*Metric, error) {
+	metrics, err := r.repository.Metrics()
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make([]*Metric, 0, len(metrics))
+
+	for i := range metrics {
+		if r.policy.AllowMetric(r.principal, metrics[i].Name) {
+			allowed = append(allowed, metrics[i])
+		}
+	}
+
+	return allowed, nil
+}
+
+// Dimensions returns dimensions allowed for principal.
+func (r *AccessRepository) Dimensions() ([]*Dimension, error) {
+	dimensions, err := r.repository.Dimensions()
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make([]*Dimension, 0, len(dimensions))
+
+	for i := range dimensions {
+		if r.policy.AllowDimension(r.principal, dimensions[i].Name) {
+			allowed = append(allowed, dimensions[i])
+		}
+	}
+
+	return allowed, nil
+}
+
+// Hierarchies returns hierarchies cut before first level which is not allowed to principal.
+func (r *AccessRepository) Hierarchies() ([]*Hierarchy, error) {
+	hierarchies, err := r.repository.Hierarchies()
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make([]*Hierarchy, 0, len(hierarchies))
+
+	for _, hierarchy := range hierarchies {
+		levels := make([]DimensionKey, 0, len(hierarchy.Levels))
+
+		for _, level := range hierarchy.Levels {
+			if !r.policy.AllowDimension(r.principal, level) {
+				break
+			}
+
+			levels = append(levels, level)
+		}
+
+		if len(levels) == 0 {
+			continue
+		}
+
+		allowed = append(allowed, &Hierarchy{
+			Name:        hierarchy.Name,
+			Description: hierarchy.Description,
+			Levels:      levels,
+		})
+	}
+
+	return allowed, nil
+}
+
+func (r *AccessRepository) checkRequest(req *ItemsRequest) error {
+	for _, key := range req.Groups {
+		if err := r.checkDimension(key); err != nil {
+			return err
+		}
+	}
+
+	for _, filter := range req.Filters {
+		if err := r.checkDimension(filter.Key); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range req.Metrics {
+		if err := r.checkMetric(name); err != nil {
+			return err
+		}
+	}
+
+	// sort key could be dimension or metric.
+	for _, order := range req.SortBy {
+		if err := r.checkDimension(order.Key); err != nil {
+			return err
+		}
+
+		if err := r.checkMetric(order.Key); err != nil {
+			return err
+		}
+	}
+
+	// search without dimensions matches groups which are checked above.
+	if req.Search != nil {
+		for _, key := range req.Search.Dimensions {
+			if err := r.checkDimension(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	if req.Top != nil {
+		if err := r.checkMetric(req.Top.Metric); err != nil {
+			return err
+		}
+	}
+
+	for _, w := range req.Windows {
+		if err := r.checkMetric(w.Metric); err != nil {
+			return err
+		}
+
+		for _, key := range append([]string{w.OrderBy}, w.PartitionBy...) {
+			if err := r.checkDimension(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *AccessRepository) checkDimension(key string) error {
+	if !r.policy.AllowDimension(r.principal, DimensionKey(key)) {
+		return &PermissionError{Kind: "dimension", Field: key}
+	}
+
+	return nil
+}
+
+func (r *AccessRepository) checkMetric(name string) error {
+	if !r.policy.AllowMetric(r.principal, name) {
+		return &PermissionError{Kind: "metric", Field: name}
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package statistica
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func testAccessPolicy() *RoleAccessPolicy {
+	return &RoleAccessPolicy{
+		Dimensions: map[DimensionKey][]string{
+			"geo_id": {"internal"},
+		},
+		Metrics: map[string][]string{
+			"total": {"internal", "billing"},
+		},
+	}
+}
+
+func TestAccessRepository_Lists(t *testing.T) {
+	t.Parallel()
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+
+	tt := []struct {
+		name       string
+		principal  *Principal
+		dimensions []DimensionKey
+		metrics    []string
+	}{
+		{
+			name:       "anonymous",
+			dimensions: []DimensionKey{"user_id"},
+			metrics:    []string{},
+		},
+		{
+			name:       "billing",
+			principal:  &Principal{ID: "b", Roles: []string{"billing"}},
+			dimensions: []DimensionKey{"user_id"},
+			metrics:    []string{"total"},
+		},
+		{
+			name:       "internal",
+			principal:  &Principal{ID: "i", Roles: []string{"internal"}},
+			dimensions: []DimensionKey{"user_id", "geo_id"},
+			metrics:    []string{"total"},
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewAccessRepository(testRepository(t, db), testAccessPolicy(), tc.principal)
+
+			dimensions, err := r.Dimensions()
+			require.NoError(t, err)
+
+			keys := make([]DimensionKey, 0, len(dimensions))
+			for j := range dimensions {
+				keys = append(keys, dimensions[j].Name)
+			}
+
+			require.Equal(t, tc.dimensions, keys)
+
+			metrics, err := r.Metrics()
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(metrics))
+			for j := range metrics {
+				names = append(names, metrics[j].Name)
+			}
+
+			require.Equal(t, tc.metrics, names)
+		})
+	}
+}
+
+func TestAccessRepository_Rejects(t *testing.T) {
+	t.Parallel()
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+
+	principal := &Principal{ID: "b", Roles: []string{"billing"}}
+	r := NewAccessRepository(testRepository(t, db), testAccessPolicy(), principal)
+
+	tt := []struct {
+		name     string
+		request  *ItemsRequest
+		expected *PermissionError
+	}{
+		{
+			name:     "group",
+			request:  &ItemsRequest{Groups: []string{"user_id", "geo_id"}},
+			expected: &PermissionError{Kind: "dimension", Field: "geo_id"},
+		},
+		{
+			name: "filter",
+			request: &ItemsRequest{Filters: []*ItemsRequestFilter{
+				{Key: "geo_id", Values: []interface{}{1}},
+			}},
+			expected: &PermissionError{Kind: "dimension", Field: "geo_id"},
+		},
+		{
+			name:     "sort",
+			request:  &ItemsRequest{SortBy: []*ItemsRequestOrder{{Key: "geo_id", Direction: "asc"}}},
+			expected: &PermissionError{Kind: "dimension", Field: "geo_id"},
+		},
+		{
+			name: "search",
+			request: &ItemsRequest{
+				Groups: []string{"user_id"},
+				Search: &SearchRequest{Term: "10.", Dimensions: []string{"geo_id"}},
+			},
+			expected: &PermissionError{Kind: "dimension", Field: "geo_id"},
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := r.Grouped(tc.request)
+			require.True(t, errors.Is(err, ErrPermissionDenied))
+
+			var permErr *PermissionError
+			require.True(t, errors.As(err, &permErr))
+			require.Equal(t, tc.expected, permErr)
+
+			_, err = r.Total(tc.request)
+			require.True(t, errors.Is(err, ErrPermissionDenied))
+
+			_, err = r.Values(tc.request)
+			require.True(t, errors.Is(err, ErrPermissionDenied))
+		})
+	}
+}
+
+func TestAccessRepository_GroupedHidesMetrics(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "total"}).AddRow(int64(1), int64(10)))
+
+	r := NewAccessRepository(testRepository(t, db), testAccessPolicy(), &Principal{ID: "guest"})
+
+	list, err := r.Grouped(&ItemsRequest{Groups: []string{"user_id"}})
+	require.NoError(t, err)
+	require.Equal(t, []*ItemRow{
+		{
+			Dimensions: map[string]interface{}{"user_id": int64(1)},
+			Metrics:    map[string]ValueNumber{},
+		},
+	}, list)
+
+	_, err = r.Grouped(&ItemsRequest{Groups: []string{"user_id"}, Metrics: []string{"total"}})
+	require.True(t, errors.Is(err, ErrPermissionDenied))
+}
+
+func TestAccessRepository_HidesExactMetrics(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repository := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "user_id", Expression: "user_id"}},
+		[]*Metric{{Name: "total", Expression: "count(*)"}, {Name: "cost", Expression: "sum(price)", Exact: true}},
+	)
+	policy := &RoleAccessPolicy{Metrics: map[string][]string{"cost": {"billing"}}}
+	r := NewAccessRepository(repository, policy, &Principal{ID: "guest"})
+
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery("SELECT").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "total", "cost"}).AddRow(int64(1), int64(10), "1.05"))
+	}
+
+	rows, err := r.Grouped(&ItemsRequest{Groups: []string{"user_id"}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]ValueNumber{"total": 10}, rows[0].Metrics)
+	require.Empty(t, rows[0].ExactMetrics)
+
+	err = r.GroupedEach(context.Background(), &ItemsRequest{Groups: []string{"user_id"}}, func(row *ItemRow) error {
+		require.Empty(t, row.ExactMetrics)
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,227 @@
+package statistica
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const defaultCountExpression = "count(*)"
+
+// AggregateTable this struct describe pre-aggregated rollup table of repository table.
+type AggregateTable struct {
+	// Table contains name of rollup table or sql expression like table.
+	Table string
+
+	// Dimensions contains expressions of dimensions which are stored in rollup table,
+	// empty expression means expression of dimension of repository.
+	Dimensions map[DimensionKey]string
+
+	// Metrics contains expressions of metrics computed from rollup table, e.g. "sum(total)" for "count(*)",
+	// empty expression means expression of metric of repository.
+	Metrics map[string]string
+
+	// Count contains expression of count of rows of repository table, e.g. "sum(events)".
+	// Rollup table without Count does not serve Values and Total without groups.
+	Count string
+
+	// DateDimension contains dimension truncated to Granularity in rollup table.
+	DateDimension DimensionKey
+
+	// Granularity contains granularity of DateDimension, only filters ">=" and "<" by values which are aligned
+	// to Granularity are served by rollup table.
+	Granularity time.Duration
+
+	// Rows contains estimated count of rows, table with less rows is preferred.
+	Rows uint64
+}
+
+// AggregateTablesSQLRepositoryOption sets rollup tables which are used instead of table of repository
+// when they are able to serve request.
+func AggregateTablesSQLRepositoryOption(tables ...*AggregateTable) SQLRepositoryOption {
+	return func(repository *SQLRepository) {
+		repository.aggregates = append(repository.aggregates, tables...)
+	}
+}
+
+// route returns repository of smallest rollup table which is able to serve request,
+// repository itself is returned if there is no such table.
+func (r *SQLRepository) route(method QueryMethod, req *ItemsRequest) *SQLRepository {
+	var best *AggregateTable
+
+	for _, table := range r.aggregates {
+		if !r.canServe(table, method, req) {
+			continue
+		}
+
+		if best == nil || table.Rows < best.Rows {
+			best = table
+		}
+	}
+
+	if best == nil {
+		return r
+	}
+
+	r.logger.Debug("route request to aggregate table", zap.String("table", best.Table))
+
+	return r.withAggregate(best, req)
+}
+
+//nolint:cyclop
+func (r *SQLRepository) canServe(table *AggregateTable, method QueryMethod, req *ItemsRequest) bool {
+	hasDimension := func(key string) bool {
+		if _, ok := r.getDimension(DimensionKey(key)); !ok {
+			// unknown dimensions are ignored by query.
+			return true
+		}
+
+		_, ok := table.Dimensions[DimensionKey(key)]
+
+		return ok
+	}
+
+	for _, group := range req.Groups {
+		if !hasDimension(group) {
+			return false
+		}
+	}
+
+	for _, order := range req.SortBy {
+		if !hasDimension(order.Key) {
+			return false
+		}
+	}
+
+	for _, filter := range req.Filters {
+		if !hasDimension(filter.Key) {
+			return false
+		}
+
+		if DimensionKey(filter.Key) == table.DateDimension && !table.alignedFilter(filter) {
+			return false
+		}
+	}
+
+	switch method {
+	case MethodGrouped:
+		// metrics of order, top, windows and cursor are selected with requested metrics.
+		for _, metric := range r.selectedMetrics(req) {
+			if _, ok := table.Metrics[metric.Name]; !ok {
+				return false
+			}
+		}
+	case MethodValues:
+		return table.Count != ""
+	case MethodTotal:
+		return table.Count != "" || len(req.Groups) > 0
+	}
+
+	return true
+}
+
+// alignedFilter returns true if filter by truncated date selects same rows of rollup table and repository table.
+// Only bounds aligned to granularity are allowed, e.g. "created < 2022-10-02" selects whole days
+// while "created <= 2022-10-02" or "created = 2022-10-02" selects part of day in repository table.
+func (t *AggregateTable) alignedFilter(filter *ItemsRequestFilter) bool {
+	if t.Granularity <= 0 {
+		return true
+	}
+
+	if filter.Condition != CondGreaterOrEq && filter.Condition != CondLess {
+		return false
+	}
+
+	values := filter.Values
+	for i := range values {
+		date, ok := parseDate(values[i])
+		if !ok || !date.Truncate(t.Granularity).Equal(date) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// selectedMetrics returns metrics of request with metrics which are used by order, top and windows of request,
+// so metrics are selected to be sorted by alias and computed by window functions.
+func (r *SQLRepository) selectedMetrics(req *ItemsRequest) []*Metric {
+	if len(req.Metrics) == 0 {
+		return r.metrics
+	}
+
+	names := append(make([]string, 0, len(req.Metrics)+len(req.SortBy)+len(req.Windows)+1), req.Metrics...)
+
+	for _, order := range req.SortBy {
+		names = append(names, order.Key)
+	}
+
+	for _, w := range req.Windows {
+		names = append(names, w.Metric)
+	}
+
+	if req.Top != nil {
+		names = append(names, req.Top.Metric)
+	}
+
+	metrics := make([]*Metric, 0, len(names))
+
+	for _, metric := range r.metrics {
+		if inStrings(names, metric.Name) {
+			metrics = append(metrics, metric)
+		}
+	}
+
+	return metrics
+}
+
+// withAggregate returns copy of repository which reads rollup table.
+func (r *SQLRepository) withAggregate(table *AggregateTable, req *ItemsRequest) *SQLRepository {
+	routed := *r
+	routed.table = table.Table
+	routed.count = table.Count
+	routed.aggregates = nil
+	routed.dimensions = make([]*Dimension, 0, len(table.Dimensions))
+	routed.mapDimensions = make(map[DimensionKey]*Dimension, len(table.Dimensions))
+	routed.metrics = make([]*Metric, 0, len(table.Metrics))
+
+	for _, dim := range r.dimensions {
+		expression, ok := table.Dimensions[dim.Name]
+		if !ok {
+			continue
+		}
+
+		aggregated := *dim
+		if expression != "" {
+			aggregated.Expression = expression
+		}
+
+		routed.dimensions = append(routed.dimensions, &aggregated)
+		routed.mapDimensions[aggregated.Name] = &aggregated
+	}
+
+	for _, metric := range r.selectedMetrics(req) {
+		expression, ok := table.Metrics[metric.Name]
+		if !ok {
+			continue
+		}
+
+		aggregated := *metric
+		if expression != "" {
+			aggregated.Expression = expression
+			aggregated.Kind = MetricKindExpression
+		}
+
+		routed.metrics = append(routed.metrics, &aggregated)
+	}
+
+	return &routed
+}
+
+func (r *SQLRepository) countExpression() string {
+	if r.count == "" {
+		return defaultCountExpression
+	}
+
+	return r.count
+}
@@ -0,0 +1,164 @@
+package statistica
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLRepository_AggregateTables(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, "events",
+		[]*Dimension{
+			{Name: "user_id", Expression: "user_id"},
+			{Name: "geo_id", Expression: "geo_id"},
+			{Name: "created", Expression: "created", Type: DimensionTypeDateTime},
+		},
+		[]*Metric{
+			{Name: "total", Expression: "count(*)"},
+			{Name: "users", Expression: "uniqExact(user_id)"},
+		},
+		AggregateTablesSQLRepositoryOption(
+			&AggregateTable{
+				Table:         "events_hourly",
+				Dimensions:    map[DimensionKey]string{"geo_id": "", "user_id": "", "created": "hour"},
+				Metrics:       map[string]string{"total": "sum(events)", "users": "uniqExactMerge(users)"},
+				Count:         "sum(events)",
+				DateDimension: "created",
+				Granularity:   time.Hour,
+				Rows:          1000,
+			},
+			&AggregateTable{
+				Table:         "events_daily",
+				Dimensions:    map[DimensionKey]string{"geo_id": "", "created": "day"},
+				Metrics:       map[string]string{"total": "sum(events)"},
+				DateDimension: "created",
+				Granularity:   24 * time.Hour,
+				Rows:          10,
+			},
+		),
+	)
+
+	tt := []struct {
+		name   string
+		method QueryMethod
+		req    *ItemsRequest
+		query  string
+	}{
+		{
+			name:   "daily",
+			method: MethodGrouped,
+			req: &ItemsRequest{
+				Groups:  []string{"geo_id"},
+				Metrics: []string{"total"},
+				Filters: []*ItemsRequestFilter{{Key: "created", Condition: CondGreaterOrEq, Values: []interface{}{"2022-10-01"}}},
+			},
+			query: "SELECT geo_id, sum(events) AS total FROM events_daily WHERE day >= ? GROUP BY geo_id",
+		},
+		{
+			name:   "hourly by date granularity",
+			method: MethodGrouped,
+			req: &ItemsRequest{
+				Groups:  []string{"geo_id"},
+				Metrics: []string{"total"},
+				Filters: []*ItemsRequestFilter{
+					{Key: "created", Condition: CondGreaterOrEq, Values: []interface{}{"2022-10-01 12:00:00"}},
+				},
+			},
+			query: "SELECT geo_id, sum(events) AS total FROM events_hourly WHERE hour >= ? GROUP BY geo_id",
+		},
+		{
+			name:   "hourly by metrics",
+			method: MethodGrouped,
+			req:    &ItemsRequest{Groups: []string{"geo_id"}},
+			query:  "SELECT geo_id, sum(events) AS total,uniqExactMerge(users) AS users FROM events_hourly GROUP BY geo_id",
+		},
+		{
+			name:   "hourly by count",
+			method: MethodValues,
+			req:    &ItemsRequest{Groups: []string{"geo_id"}},
+			query:  "SELECT geo_id, sum(events) AS total FROM events_hourly GROUP BY geo_id",
+		},
+		{
+			name:   "raw table by date granularity",
+			method: MethodGrouped,
+			req: &ItemsRequest{
+				Groups:  []string{"geo_id"},
+				Filters: []*ItemsRequestFilter{{Key: "created", Condition: CondLess, Values: []interface{}{"2022-10-01 12:30:00"}}},
+			},
+			query: "SELECT geo_id, count(*) AS total,uniqExact(user_id) AS users FROM events WHERE created < ? GROUP BY geo_id",
+		},
+		{
+			name:   "raw table by inclusive date bound",
+			method: MethodGrouped,
+			req: &ItemsRequest{
+				Groups:  []string{"geo_id"},
+				Metrics: []string{"total"},
+				Filters: []*ItemsRequestFilter{{Key: "created", Condition: CondLessOrEq, Values: []interface{}{"2022-10-01"}}},
+			},
+			query: "SELECT geo_id, count(*) AS total FROM events WHERE created <= ? GROUP BY geo_id",
+		},
+		{
+			name:   "hourly by order metric",
+			method: MethodGrouped,
+			req: &ItemsRequest{
+				Limit:   2,
+				Groups:  []string{"geo_id"},
+				Metrics: []string{"total"},
+				SortBy:  []*ItemsRequestOrder{{Key: "users", Direction: "DESC"}},
+			},
+			query: "SELECT geo_id, sum(events) AS total,uniqExactMerge(users) AS users FROM events_hourly " +
+				"GROUP BY geo_id ORDER BY users DESC LIMIT 2",
+		},
+		{
+			name:   "hourly by window metric",
+			method: MethodGrouped,
+			req: &ItemsRequest{
+				Groups:  []string{"geo_id"},
+				Metrics: []string{"total"},
+				Windows: []*WindowMetric{{Metric: "users", Function: WindowRank}},
+			},
+			query: "SELECT geo_id, sum(events) AS total,uniqExactMerge(users) AS users FROM events_hourly GROUP BY geo_id",
+		},
+		{
+			name:   "hourly total by count",
+			method: MethodTotal,
+			req:    &ItemsRequest{},
+			query:  "SELECT sum(events) AS total FROM events_hourly",
+		},
+		{
+			name:   "hourly by dimension",
+			method: MethodValues,
+			req:    &ItemsRequest{Groups: []string{"user_id"}, SortBy: []*ItemsRequestOrder{{Key: "user_id"}}},
+			query:  "SELECT user_id, sum(events) AS total FROM events_hourly GROUP BY user_id ORDER BY user_id ",
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			plan, err := r.Explain(tc.method, tc.req)
+			require.NoError(t, err)
+			require.Equal(t, tc.query, regexp.MustCompile(`\s+`).ReplaceAllString(plan.Query, " "))
+		})
+	}
+
+	mock.
+		ExpectQuery("^" + regexp.QuoteMeta("SELECT geo_id, sum(events) AS total FROM events_daily GROUP BY geo_id") + "$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total"}).AddRow(int64(1), int64(10)))
+
+	rows, err := r.Grouped(&ItemsRequest{Groups: []string{"geo_id"}, Metrics: []string{"total"}})
+	require.NoError(t, err)
+	require.Equal(t, ValueNumber(10), rows[0].Metrics["total"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,180 @@
+package statistica
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AuditRecord this struct describes one executed query.
+type AuditRecord struct {
+	// Time contains start time of query.
+	Time time.Time `json:"time"`
+
+	// Caller contains ID of Principal from context.
+	Caller string `json:"caller"`
+
+	// Method contains name of called repository method.
+	Method QueryMethod `json:"method"`
+
+	// Table contains table name or sql expression like table.
+	Table string `json:"table"`
+
+	// Request contains normalized request.
+	Request *ItemsRequest `json:"request"`
+
+	// Query contains generated SQL.
+	Query string `json:"query"`
+
+	// Params contains bound parameters of Query.
+	Params []interface{} `json:"params"`
+
+	// Duration contains duration of call.
+	Duration time.Duration `json:"duration"`
+
+	// Rows contains count of returned rows.
+	Rows int `json:"rows"`
+
+	// Error contains error message of failed call.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditSink common interface of destination of AuditRecord.
+type AuditSink interface {
+	// Record stores record.
+	Record(ctx context.Context, record *AuditRecord)
+}
+
+// AuditSQLRepositoryOption sets sink which records queries slower than threshold, zero threshold records all queries.
+func AuditSQLRepositoryOption(sink AuditSink, threshold time.Duration) SQLRepositoryOption {
+	return func(repository *SQLRepository) {
+		repository.auditSink = sink
+		repository.auditThreshold = threshold
+	}
+}
+
+func (r *SQLRepository) audit(ctx context.Context, o *observation, duration time.Duration, rows int, err error) {
+	if r.auditSink == nil || duration < r.auditThreshold {
+		return
+	}
+
+	record := &AuditRecord{
+		Time:     o.started,
+		Method:   o.method,
+		Table:    r.table,
+		Request:  r.normalizeRequest(o.request),
+		Query:    o.sql,
+		Params:   o.params,
+		Duration: duration,
+		Rows:     rows,
+	}
+
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		record.Caller = principal.ID
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	r.auditSink.Record(ctx, record)
+}
+
+// normalizeRequest returns copy of request without groups and filters unknown for repository,
+// filters without values are kept only if they are applied by condition, e.g. IS NULL.
+func (r *SQLRepository) normalizeRequest(req *ItemsRequest) *ItemsRequest {
+	if req == nil {
+		return nil
+	}
+
+	normalized := *req
+	normalized.Groups = make([]string, 0, len(req.Groups))
+	normalized.Filters = make([]*ItemsRequestFilter, 0, len(req.Filters))
+
+	for _, key := range req.Groups {
+		if _, exists := r.getDimension(DimensionKey(key)); exists {
+			normalized.Groups = append(normalized.Groups, key)
+		}
+	}
+
+	for _, filter := range req.Filters {
+		if _, exists := r.getDimension(DimensionKey(filter.Key)); !exists {
+			continue
+		}
+
+		switch {
+		case len(filter.Values) > 0, filter.Condition == CondIsNull, filter.Condition == CondIsNotNull,
+			filter.Condition == CondLabel:
+			normalized.Filters = append(normalized.Filters, filter)
+		}
+	}
+
+	return &normalized
+}
+
+// ZapAuditSink implementation of AuditSink which writes records to zap logger.
+type ZapAuditSink struct {
+	logger *zap.Logger
+}
+
+// NewZapAuditSink returns new instance of ZapAuditSink.
+func NewZapAuditSink(logger *zap.Logger) *ZapAuditSink {
+	return &ZapAuditSink{logger: logger}
+}
+
+// Record writes record to logger.
+func (s *ZapAuditSink) Record(_ context.Context, record *AuditRecord) {
+	fields := []zap.Field{
+		zap.String("caller", record.Caller),
+		zap.String("method", string(record.Method)),
+		zap.String("table", record.Table),
+		zap.Reflect("request", record.Request),
+		zap.String("query", record.Query),
+		zap.Reflect("params", record.Params),
+		zap.Duration("duration", record.Duration),
+		zap.Int("rows", record.Rows),
+	}
+
+	if record.Error != "" {
+		s.logger.Warn("query audit", append(fields, zap.String("error", record.Error))...)
+
+		return
+	}
+
+	s.logger.Info("query audit", fields...)
+}
+
+// JSONLinesAuditSink implementation of AuditSink which writes records as JSON lines, e.g. to file.
+type JSONLinesAuditSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONLinesAuditSink returns new instance of JSONLinesAuditSink.
+func NewJSONLinesAuditSink(w io.Writer) *JSONLinesAuditSink {
+	return &JSONLinesAuditSink{encoder: json.NewEncoder(w)}
+}
+
+// Record writes record as one JSON line, failed writes are dropped.
+func (s *JSONLinesAuditSink) Record(_ context.Context, record *AuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = s.encoder.Encode(record)
+}
+
+// ChanAuditSink implementation of AuditSink which sends records to channel.
+// Records are dropped when channel is full.
+type ChanAuditSink chan<- *AuditRecord
+
+// Record sends record to channel.
+func (s ChanAuditSink) Record(_ context.Context, record *AuditRecord) {
+	select {
+	case s <- record:
+	default:
+	}
+}
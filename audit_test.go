@@ -0,0 +1,89 @@
+package statistica
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLRepository_Audit(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	records := make(chan *AuditRecord, 1)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "user_id", Expression: "user_id"}},
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+		AuditSQLRepositoryOption(ChanAuditSink(records), 0),
+	)
+
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "total"}).AddRow(int64(1), int64(10)))
+
+	ctx := ContextWithPrincipal(context.Background(), &Principal{ID: "dashboard-1"})
+	_, err = r.GroupedContext(ctx, &ItemsRequest{
+		Groups: []string{"user_id", "unknown"},
+		Filters: []*ItemsRequestFilter{
+			{Key: "user_id", Values: []interface{}{1}},
+			{Key: "user_id", Condition: CondIsNotNull},
+			{Key: "user_id"},
+			{Key: "unknown", Values: []interface{}{2}},
+		},
+		SortBy: []*ItemsRequestOrder{{Key: "total", Direction: "DESC"}, {Key: "user_id", Direction: "ASC"}},
+	})
+	require.NoError(t, err)
+
+	record := <-records
+	require.Equal(t, "dashboard-1", record.Caller)
+	require.Equal(t, MethodGrouped, record.Method)
+	require.Equal(t,
+		"SELECT user_id, count(*) AS total FROM test_table  WHERE user_id IN (?) AND user_id IS NOT NULL GROUP BY  user_id "+
+			"ORDER BY total DESC,user_id ASC",
+		record.Query,
+	)
+	require.Equal(t, []interface{}{1}, record.Params)
+	require.Equal(t, 1, record.Rows)
+	require.Equal(t, []string{"user_id"}, record.Request.Groups)
+	require.Len(t, record.Request.Filters, 2)
+	require.Len(t, record.Request.SortBy, 2)
+	require.Empty(t, record.Error)
+}
+
+func TestSQLRepository_AuditThreshold(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+
+	r := NewSQLRepository(db, testTable, nil,
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+		AuditSQLRepositoryOption(NewJSONLinesAuditSink(buf), time.Millisecond*50),
+	)
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(int64(1)))
+	mock.ExpectQuery("SELECT").WillDelayFor(time.Millisecond * 100).
+		WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(int64(1)))
+
+	_, err = r.Total(&ItemsRequest{})
+	require.NoError(t, err)
+	require.Zero(t, buf.Len())
+
+	_, err = r.Total(&ItemsRequest{})
+	require.NoError(t, err)
+
+	record := &AuditRecord{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), record))
+	require.Equal(t, MethodTotal, record.Method)
+	require.Equal(t, "SELECT count(*) AS total FROM test_table", record.Query)
+	require.GreaterOrEqual(t, record.Duration, time.Millisecond*50)
+}
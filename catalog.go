@@ -0,0 +1,207 @@
+package statistica
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrCubeNotFound returns when catalog has no cube with name of request.
+	ErrCubeNotFound = errors.New("cube not found")
+	// ErrCubeExists returns when cube with same name is already registered in catalog.
+	ErrCubeExists = errors.New("cube already exists")
+)
+
+// Cube this struct describe metadata of cube registered in catalog.
+type Cube struct {
+	// Name contains name of cube, requests are routed by it.
+	Name string `json:"name"`
+
+	// Description contains description of cube.
+	Description string `json:"description"`
+
+	// Dimensions contains allowed dimensions of cube.
+	Dimensions []*Dimension `json:"dimensions"`
+
+	// Metrics contains allowed metrics of cube.
+	Metrics []*Metric `json:"metrics"`
+
+	// Hierarchies contains hierarchies of dimensions of cube.
+	Hierarchies []*Hierarchy `json:"hierarchies"`
+}
+
+type catalogEntry struct {
+	description string
+	repository  ReadRepository
+}
+
+// Catalog contains named cubes, each cube is repository with own connection, table, dimensions and metrics.
+// Requests are routed to repository by ItemsRequest.Cube.
+type Catalog struct {
+	mu    sync.RWMutex
+	cubes map[string]*catalogEntry
+}
+
+// NewCatalog returns new instance of Catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{
+		cubes: make(map[string]*catalogEntry),
+	}
+}
+
+// Register adds repository to catalog as cube with name.
+func (c *Catalog) Register(name, description string, repository ReadRepository) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.cubes[name]; ok {
+		return fmt.Errorf("%w: %s", ErrCubeExists, name)
+	}
+
+	c.cubes[name] = &catalogEntry{
+		description: description,
+		repository:  repository,
+	}
+
+	return nil
+}
+
+// Repository returns repository of cube by name.
+func (c *Catalog) Repository(name string) (ReadRepository, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.cubes[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrCubeNotFound, name)
+	}
+
+	return entry.repository, nil
+}
+
+// Cubes returns metadata of registered cubes sorted by name.
+func (c *Catalog) Cubes() ([]*Cube, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.cubes))
+	for name := range c.cubes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	cubes := make([]*Cube, 0, len(names))
+
+	for _, name := range names {
+		entry := c.cubes[name]
+
+		dimensions, err := entry.repository.Dimensions()
+		if err != nil {
+			return nil, err
+		}
+
+		metrics, err := entry.repository.Metrics()
+		if err != nil {
+			return nil, err
+		}
+
+		hierarchies, err := entry.repository.Hierarchies()
+		if err != nil {
+			return nil, err
+		}
+
+		cubes = append(cubes, &Cube{
+			Name:        name,
+			Description: entry.description,
+			Dimensions:  dimensions,
+			Metrics:     metrics,
+			Hierarchies: hierarchies,
+		})
+	}
+
+	return cubes, nil
+}
+
+// Total returns total rows of cube by query ItemsRequest.
+func (c *Catalog) Total(req *ItemsRequest) (uint64, error) {
+	return c.TotalContext(context.Background(), req)
+}
+
+// TotalContext returns total rows of cube by query ItemsRequest.
+func (c *Catalog) TotalContext(ctx context.Context, req *ItemsRequest) (uint64, error) {
+	repository, err := c.Repository(req.Cube)
+	if err != nil {
+		return 0, err
+	}
+
+	return repository.TotalContext(ctx, req)
+}
+
+// Values returns rows ValueResponse of cube by query ItemsRequest.
+func (c *Catalog) Values(req *ItemsRequest) ([]*ValueResponse, error) {
+	return c.ValuesContext(context.Background(), req)
+}
+
+// ValuesContext returns rows ValueResponse of cube by query ItemsRequest.
+func (c *Catalog) ValuesContext(ctx context.Context, req *ItemsRequest) ([]*ValueResponse, error) {
+	repository, err := c.Repository(req.Cube)
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.ValuesContext(ctx, req)
+}
+
+// Grouped returns rows ItemRow of cube by query ItemsRequest.
+func (c *Catalog) Grouped(req *ItemsRequest) ([]*ItemRow, error) {
+	return c.GroupedContext(context.Background(), req)
+}
+
+// GroupedContext returns rows ItemRow of cube by query ItemsRequest.
+func (c *Catalog) GroupedContext(ctx context.Context, req *ItemsRequest) ([]*ItemRow, error) {
+	repository, err := c.Repository(req.Cube)
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.GroupedContext(ctx, req)
+}
+
+// Page returns rows, total and summary of cube by query ItemsRequest.
+func (c *Catalog) Page(req *ItemsRequest) (*ItemsResponse, error) {
+	return c.PageContext(context.Background(), req)
+}
+
+// PageContext returns rows, total and summary of cube by query ItemsRequest.
+func (c *Catalog) PageContext(ctx context.Context, req *ItemsRequest) (*ItemsResponse, error) {
+	repository, err := c.Repository(req.Cube)
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.PageContext(ctx, req)
+}
+
+// ValuesEach calls fn for each value ValueResponse of cube by query ItemsRequest.
+func (c *Catalog) ValuesEach(ctx context.Context, req *ItemsRequest, fn func(value *ValueResponse) error) error {
+	repository, err := c.Repository(req.Cube)
+	if err != nil {
+		return err
+	}
+
+	return valuesEach(ctx, repository, req, fn)
+}
+
+// GroupedEach calls fn for each row ItemRow of cube by query ItemsRequest.
+func (c *Catalog) GroupedEach(ctx context.Context, req *ItemsRequest, fn func(row *ItemRow) error) error {
+	repository, err := c.Repository(req.Cube)
+	if err != nil {
+		return err
+	}
+
+	return groupedEach(ctx, repository, req, fn)
+}
@@ -0,0 +1,60 @@
+package statistica
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog(t *testing.T) {
+	t.Parallel()
+
+	eventsDB, eventsMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	ordersDB, ordersMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	catalog := NewCatalog()
+	require.NoError(t, catalog.Register("events", "Ad events", testRepository(t, eventsDB)))
+	require.NoError(t, catalog.Register("orders", "Shop orders", NewSQLRepository(ordersDB, "orders",
+		[]*Dimension{{Name: "shop_id", Expression: "shop_id"}},
+		[]*Metric{{Name: "amount", Expression: "sum(amount)"}},
+	)))
+
+	err = catalog.Register("events", "", testRepository(t, eventsDB))
+	require.True(t, errors.Is(err, ErrCubeExists))
+
+	cubes, err := catalog.Cubes()
+	require.NoError(t, err)
+	require.Len(t, cubes, 2)
+	require.Equal(t, "events", cubes[0].Name)
+	require.Equal(t, "Ad events", cubes[0].Description)
+	require.Len(t, cubes[0].Dimensions, 2)
+	require.Equal(t, "amount", cubes[1].Metrics[0].Name)
+
+	ordersMock.
+		ExpectQuery("^" + regexp.QuoteMeta("SELECT shop_id, sum(amount) AS amount FROM orders GROUP BY shop_id") + "$").
+		WillReturnRows(sqlmock.NewRows([]string{"shop_id", "amount"}).AddRow(int64(1), 10.5))
+
+	rows, err := catalog.Grouped(&ItemsRequest{Cube: "orders", Groups: []string{"shop_id"}})
+	require.NoError(t, err)
+	require.Equal(t, ValueNumber(10.5), rows[0].Metrics["amount"])
+
+	eventsMock.
+		ExpectQuery("^" + regexp.QuoteMeta("SELECT count(*) AS total FROM test_table") + "$").
+		WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(uint64(7)))
+
+	total, err := catalog.Total(&ItemsRequest{Cube: "events"})
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), total)
+
+	_, err = catalog.Values(&ItemsRequest{Cube: "clicks"})
+	require.True(t, errors.Is(err, ErrCubeNotFound))
+
+	require.NoError(t, eventsMock.ExpectationsWereMet())
+	require.NoError(t, ordersMock.ExpectationsWereMet())
+}
@@ -9,7 +9,13 @@ const (
 	CondNotEq  Condition = "neq"
 	CondNotEq2 Condition = "!="
 
-	CondLike Condition = "like"
+	CondLike   Condition = "like"
+	CondPrefix Condition = "prefix"
+
+	CondLabel Condition = "label"
+
+	CondIsNull    Condition = "is_null"
+	CondIsNotNull Condition = "is_not_null"
 
 	CondGreater     Condition = ">"
 	CondGreaterOrEq Condition = ">="
@@ -0,0 +1,275 @@
+package statistica
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidCursor returns when cursor could not be decoded or was created for request with other order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorRequest this struct represents keyset pagination of Grouped, rows are ordered by SortBy
+// and then by groups which are not in SortBy, so order of rows is deterministic. Rows could not be sorted
+// by window metrics with cursor, cursor could not be combined with Top or Totals.
+type CursorRequest struct {
+	// After contains cursor of last row of previous page, first page is requested with empty cursor.
+	After string
+}
+
+type cursorPayload struct {
+	Keys   []string      `json:"k"`
+	Values []interface{} `json:"v"`
+}
+
+// cursorOrder returns order of request with groups as tie-breaker.
+func cursorOrder(req *ItemsRequest) []*ItemsRequestOrder {
+	order := make([]*ItemsRequestOrder, 0, len(req.SortBy)+len(req.Groups))
+	sorted := make(map[string]bool, len(req.SortBy))
+
+	for _, item := range req.SortBy {
+		order = append(order, item)
+		sorted[item.Key] = true
+	}
+
+	for _, group := range req.Groups {
+		if !sorted[group] {
+			order = append(order, &ItemsRequestOrder{Key: group, Direction: "ASC"})
+		}
+	}
+
+	return order
+}
+
+// NextCursor returns cursor of page which follows rows of request, empty string if rows are last page.
+func NextCursor(req *ItemsRequest, rows []*ItemRow) string {
+	if req.Cursor == nil || len(rows) == 0 || req.Limit <= 0 || len(rows) < req.Limit {
+		return ""
+	}
+
+	last := rows[len(rows)-1]
+	payload := cursorPayload{}
+
+	for _, item := range cursorOrder(req) {
+		payload.Keys = append(payload.Keys, item.Key)
+
+		if value, ok := last.Dimensions[item.Key]; ok {
+			payload.Values = append(payload.Values, value)
+		} else if exact, ok := last.ExactMetrics[item.Key]; ok && !exact.isNull() {
+			// exact value is encoded as string, so it is not rounded by float64.
+			payload.Values = append(payload.Values, exact.String())
+		} else {
+			payload.Values = append(payload.Values, last.Metrics[item.Key])
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns values of sort keys of last row of previous page, nil for first page.
+func (r *SQLRepository) decodeCursor(req *ItemsRequest) ([]interface{}, error) {
+	if req.Cursor == nil || req.Cursor.After == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(req.Cursor.After)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	payload := cursorPayload{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	order := cursorOrder(req)
+	if len(payload.Keys) != len(order) || len(payload.Values) != len(order) {
+		return nil, fmt.Errorf("%w: order of request is changed", ErrInvalidCursor)
+	}
+
+	values := make([]interface{}, len(order))
+
+	for i, item := range order {
+		if payload.Keys[i] != item.Key {
+			return nil, fmt.Errorf("%w: order of request is changed", ErrInvalidCursor)
+		}
+
+		value := payload.Values[i]
+		if number, ok := value.(json.Number); ok {
+			if n, err := number.Int64(); err == nil {
+				value = n
+			} else if value, err = number.Float64(); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+			}
+		}
+
+		if dim, ok := r.getDimension(DimensionKey(item.Key)); ok {
+			if dim.Type != "" && value != nil {
+				if value, err = dim.Coerce(value); err != nil {
+					return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+				}
+			}
+		} else if metric := r.getMetric(item.Key); metric == nil {
+			return nil, fmt.Errorf("%w: unknown sort key %q", ErrInvalidCursor, item.Key)
+		} else if exact, ok := value.(string); ok {
+			if _, err := ParseDecimal(exact); err != nil || !metric.Exact {
+				return nil, fmt.Errorf("%w: invalid value of metric %q", ErrInvalidCursor, item.Key)
+			}
+		}
+
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// checkCursor returns error if cursor is requested with synthetic rows of Top or Totals,
+// last row of such page is not a group which could be sought after.
+func checkCursor(req *ItemsRequest) error {
+	if req.Cursor != nil && (req.Top != nil || req.Totals) {
+		return fmt.Errorf("%w: cursor is not supported with top or totals", ErrInvalidCursor)
+	}
+
+	return nil
+}
+
+// seekRequest returns request with decoded values of cursor which are used by seek condition.
+// Cursor supports sort by dimensions and metrics only, values of window metrics are computed after rows are selected.
+func (r *SQLRepository) seekRequest(req *ItemsRequest) (*ItemsRequest, error) {
+	if req.Cursor == nil {
+		return req, nil
+	}
+
+	for _, item := range req.SortBy {
+		if _, ok := r.getDimension(DimensionKey(item.Key)); !ok && r.getMetric(item.Key) == nil {
+			return nil, fmt.Errorf("%w: sort by %q is not supported", ErrInvalidCursor, item.Key)
+		}
+	}
+
+	values, err := r.decodeCursor(req)
+	if err != nil {
+		return nil, err
+	}
+
+	seek := *req
+	seek.after = values
+
+	return &seek, nil
+}
+
+// nullsFirst returns true if NULL values are sorted before other values by dialect in direction of order.
+func (d Dialect) nullsFirst(desc bool) bool {
+	switch d {
+	case DialectPostgres:
+		return desc
+	case DialectClickHouse:
+		return false
+	}
+
+	return !desc
+}
+
+// seekCondition returns condition which matches rows after cursor and true if condition contains metrics,
+// so it is applied in HAVING. NULL values are compared by position of NULL in order of dialect.
+func (r *SQLRepository) seekCondition(req *ItemsRequest, params *[]interface{}) (string, bool) {
+	if req.after == nil {
+		return "", false
+	}
+
+	order := cursorOrder(req)
+	expressions := make([]string, len(order))
+	having := false
+
+	for i, item := range order {
+		if dim, ok := r.getDimension(DimensionKey(item.Key)); ok {
+			expressions[i] = dim.Expression
+
+			continue
+		}
+
+		expressions[i] = r.metricExpression(r.getMetric(item.Key))
+		having = true
+	}
+
+	// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+	conditions := make([]string, 0, len(order))
+
+	for i, item := range order {
+		condition := make([]string, 0, i+1)
+		seek := make([]interface{}, 0, i+1)
+
+		for j := 0; j < i; j++ {
+			condition = append(condition, seekEqual(expressions[j], req.after[j], &seek))
+		}
+
+		after, ok := r.seekAfter(expressions[i], req.after[i], strings.EqualFold(item.Direction, "DESC"), &seek)
+		if !ok {
+			continue
+		}
+
+		*params = append(*params, seek...)
+		conditions = append(conditions, "("+strings.Join(append(condition, after), " AND ")+")")
+	}
+
+	if len(conditions) == 0 {
+		// cursor is last row of order.
+		return "(1 = 0)", having
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", having
+}
+
+// seekEqual returns condition which matches rows with value of expression equal to value of cursor.
+func seekEqual(expression string, value interface{}, params *[]interface{}) string {
+	if value == nil {
+		return expression + " IS NULL"
+	}
+
+	*params = append(*params, value)
+
+	return expression + " = ?"
+}
+
+// seekAfter returns condition which matches rows with value of expression after value of cursor,
+// false if no value follows NULL.
+func (r *SQLRepository) seekAfter(expression string, value interface{}, desc bool, params *[]interface{}) (string, bool) {
+	nullsFirst := r.dialect.nullsFirst(desc)
+
+	if value == nil {
+		return expression + " IS NOT NULL", nullsFirst
+	}
+
+	operator := ">"
+	if desc {
+		operator = "<"
+	}
+
+	*params = append(*params, value)
+
+	if nullsFirst {
+		return expression + " " + operator + " ?", true
+	}
+
+	return "(" + expression + " " + operator + " ? OR " + expression + " IS NULL)", true
+}
+
+// applyHaving adds seek condition of cursor with metrics.
+func (r *SQLRepository) applyHaving(req *ItemsRequest, query *string, params *[]interface{}) {
+	seek := make([]interface{}, 0)
+
+	if condition, having := r.seekCondition(req, &seek); having {
+		*query += " HAVING " + condition
+		*params = append(*params, seek...)
+	}
+}
@@ -0,0 +1,233 @@
+package statistica
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func testCursorRepository(t *testing.T) *SQLRepository {
+	t.Helper()
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return NewSQLRepository(db, testTable,
+		[]*Dimension{
+			{Name: "geo_id", Expression: "geo_id", Type: DimensionTypeInt},
+			{Name: "domain", Expression: "domain"},
+		},
+		[]*Metric{{Name: "cost", Expression: "sum(price)"}},
+		DialectSQLRepositoryOption(DialectPostgres),
+	)
+}
+
+func TestSQLRepository_CursorByMetric(t *testing.T) {
+	t.Parallel()
+
+	r := testCursorRepository(t)
+
+	req := &ItemsRequest{
+		Limit:  2,
+		Offset: 10,
+		Groups: []string{"geo_id", "domain"},
+		SortBy: []*ItemsRequestOrder{{Key: "cost", Direction: "DESC"}},
+		Cursor: &CursorRequest{},
+	}
+
+	plan, err := r.Explain(MethodGrouped, req)
+	require.NoError(t, err)
+	require.Equal(t,
+		"SELECT geo_id,domain, sum(price) AS cost FROM test_table  GROUP BY  geo_id,domain "+
+			"ORDER BY cost DESC,geo_id ASC,domain ASC LIMIT 2 OFFSET 10",
+		plan.Query,
+	)
+
+	rows := []*ItemRow{
+		{Dimensions: map[string]interface{}{"geo_id": int64(2), "domain": "a.com"}, Metrics: map[string]ValueNumber{"cost": 50}},
+		{Dimensions: map[string]interface{}{"geo_id": int64(1), "domain": "b.com"}, Metrics: map[string]ValueNumber{"cost": 30}},
+	}
+
+	next := NextCursor(req, rows)
+	require.NotEmpty(t, next)
+	require.Empty(t, NextCursor(req, rows[:1]))
+
+	req.Cursor = &CursorRequest{After: next}
+
+	plan, err = r.Explain(MethodGrouped, req)
+	require.NoError(t, err)
+	require.Equal(t,
+		"SELECT geo_id,domain, sum(price) AS cost FROM test_table  GROUP BY  geo_id,domain "+
+			"HAVING ((sum(price) < $1) OR (sum(price) = $2 AND (geo_id > $3 OR geo_id IS NULL)) "+
+			"OR (sum(price) = $4 AND geo_id = $5 AND (domain > $6 OR domain IS NULL))) "+
+			"ORDER BY cost DESC,geo_id ASC,domain ASC LIMIT 2",
+		plan.Query,
+	)
+	require.Equal(t, []interface{}{int64(30), int64(30), int64(1), int64(30), int64(1), "b.com"}, plan.Params)
+}
+
+func TestSQLRepository_CursorByDimensions(t *testing.T) {
+	t.Parallel()
+
+	r := testCursorRepository(t)
+
+	req := &ItemsRequest{
+		Limit:   1,
+		Groups:  []string{"geo_id"},
+		Filters: []*ItemsRequestFilter{{Key: "domain", Condition: CondEq, Values: []interface{}{"a.com"}}},
+		Cursor:  &CursorRequest{},
+	}
+
+	req.Cursor.After = NextCursor(req, []*ItemRow{{Dimensions: map[string]interface{}{"geo_id": int64(7)}}})
+
+	plan, err := r.Explain(MethodGrouped, req)
+	require.NoError(t, err)
+	require.Equal(t,
+		"SELECT geo_id, sum(price) AS cost FROM test_table  WHERE domain IN ($1) AND (((geo_id > $2 OR geo_id IS NULL))) "+
+			"GROUP BY  geo_id ORDER BY geo_id ASC LIMIT 1",
+		plan.Query,
+	)
+	require.Equal(t, []interface{}{"a.com", int64(7)}, plan.Params)
+
+	plan, err = r.Explain(MethodTotal, req)
+	require.NoError(t, err)
+	require.NotContains(t, plan.Query, "geo_id >")
+}
+
+func TestSQLRepository_CursorNulls(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name    string
+		dialect Dialect
+		where   string
+		params  []interface{}
+	}{
+		{
+			name:    "nulls last",
+			dialect: DialectPostgres,
+			where:   "WHERE ((geo_id IS NULL AND (domain > $1 OR domain IS NULL)))",
+			params:  []interface{}{"a.com"},
+		},
+		{
+			name:    "nulls first",
+			dialect: DialectSQLite,
+			where:   "WHERE ((geo_id IS NOT NULL) OR (geo_id IS NULL AND domain > ?))",
+			params:  []interface{}{"a.com"},
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, _, err := sqlmock.New()
+			require.NoError(t, err)
+
+			r := NewSQLRepository(db, testTable,
+				[]*Dimension{{Name: "geo_id", Expression: "geo_id"}, {Name: "domain", Expression: "domain"}},
+				[]*Metric{{Name: "cost", Expression: "sum(price)"}},
+				DialectSQLRepositoryOption(tc.dialect),
+			)
+
+			req := &ItemsRequest{Limit: 1, Groups: []string{"geo_id", "domain"}, Cursor: &CursorRequest{}}
+			req.Cursor.After = NextCursor(req, []*ItemRow{{Dimensions: map[string]interface{}{"geo_id": nil, "domain": "a.com"}}})
+
+			plan, err := r.Explain(MethodGrouped, req)
+			require.NoError(t, err)
+			require.Contains(t, plan.Query, tc.where)
+			require.Equal(t, tc.params, plan.Params)
+		})
+	}
+}
+
+func TestSQLRepository_CursorExactMetric(t *testing.T) {
+	t.Parallel()
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}},
+		[]*Metric{{Name: "cost", Expression: "sum(price)", Exact: true}},
+		DialectSQLRepositoryOption(DialectPostgres),
+	)
+
+	exact, err := ParseDecimal("0.30000000000000000001")
+	require.NoError(t, err)
+
+	req := &ItemsRequest{
+		Limit:  1,
+		Groups: []string{"geo_id"},
+		SortBy: []*ItemsRequestOrder{{Key: "cost", Direction: "DESC"}},
+		Cursor: &CursorRequest{},
+	}
+	req.Cursor.After = NextCursor(req, []*ItemRow{{
+		Dimensions:   map[string]interface{}{"geo_id": int64(1)},
+		Metrics:      map[string]ValueNumber{"cost": 0.3},
+		ExactMetrics: map[string]Decimal{"cost": exact},
+	}})
+
+	plan, err := r.Explain(MethodGrouped, req)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"0.30000000000000000001", "0.30000000000000000001", int64(1)}, plan.Params)
+}
+
+func TestSQLRepository_CursorInvalid(t *testing.T) {
+	t.Parallel()
+
+	r := testCursorRepository(t)
+
+	req := &ItemsRequest{Limit: 1, Groups: []string{"geo_id"}, Cursor: &CursorRequest{}}
+	after := NextCursor(req, []*ItemRow{{Dimensions: map[string]interface{}{"geo_id": int64(7)}}})
+
+	tt := []struct {
+		name string
+		req  *ItemsRequest
+	}{
+		{
+			name: "not base64",
+			req:  &ItemsRequest{Groups: []string{"geo_id"}, Cursor: &CursorRequest{After: "!"}},
+		},
+		{
+			name: "changed order",
+			req:  &ItemsRequest{Groups: []string{"domain"}, Cursor: &CursorRequest{After: after}},
+		},
+		{
+			name: "totals",
+			req:  &ItemsRequest{Limit: 2, Groups: []string{"geo_id"}, Totals: true, Cursor: &CursorRequest{}},
+		},
+		{
+			name: "top",
+			req: &ItemsRequest{
+				Groups: []string{"geo_id"},
+				Top:    &TopRequest{N: 2, Metric: "cost"},
+				Cursor: &CursorRequest{},
+			},
+		},
+		{
+			name: "sort by window",
+			req: &ItemsRequest{
+				Groups:  []string{"geo_id"},
+				SortBy:  []*ItemsRequestOrder{{Key: "cost_rank", Direction: "ASC"}},
+				Windows: []*WindowMetric{{Metric: "cost", Function: WindowRank}},
+				Cursor:  &CursorRequest{},
+			},
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := r.Grouped(tc.req)
+			require.True(t, errors.Is(err, ErrInvalidCursor))
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package statistica
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidDecimal returns when value could not be converted to Decimal.
+var ErrInvalidDecimal = errors.New("invalid decimal")
+
+// DecimalEncoding special type for represent JSON encoding of Decimal.
+type DecimalEncoding int
+
+const (
+	// DecimalEncodingNumber encodes Decimal as JSON number, it is default encoding.
+	DecimalEncodingNumber DecimalEncoding = iota
+	// DecimalEncodingString encodes Decimal as JSON string for clients which parse numbers as float64.
+	DecimalEncodingString
+)
+
+// DecimalEncodingSQLRepositoryOption sets JSON encoding of exact metric values.
+func DecimalEncodingSQLRepositoryOption(encoding DecimalEncoding) SQLRepositoryOption {
+	return func(repository *SQLRepository) {
+		repository.decimalEncoding = encoding
+	}
+}
+
+// Decimal exact decimal number, value is unscaled integer multiplied by 10^-scale.
+// Zero value of Decimal is 0.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+	encoding DecimalEncoding
+}
+
+// NewDecimal returns Decimal unscaled * 10^-scale, nil unscaled represents NULL.
+func NewDecimal(unscaled *big.Int, scale int) Decimal {
+	if unscaled == nil {
+		return Decimal{}
+	}
+
+	d := Decimal{unscaled: new(big.Int).Set(unscaled), scale: scale}
+	if scale < 0 {
+		d.unscaled.Mul(d.unscaled, pow10(-scale))
+		d.scale = 0
+	}
+
+	return d
+}
+
+// ParseDecimal returns Decimal parsed from string like "-123.45" or "1.5e3".
+// Non-finite values like "nan" or "-inf" are parsed as NULL.
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exponent := strings.TrimSpace(s), 0
+
+	switch strings.ToLower(strings.TrimLeft(mantissa, "+-")) {
+	case "nan", "inf", "infinity":
+		return Decimal{}, nil
+	}
+
+	if i := strings.IndexAny(mantissa, "eE"); i >= 0 {
+		e, err := strconv.Atoi(mantissa[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+
+		mantissa, exponent = mantissa[:i], e
+	}
+
+	scale := 0
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		scale = len(mantissa) - i - 1
+		mantissa = mantissa[:i] + mantissa[i+1:]
+	}
+
+	unscaled, ok := new(big.Int).SetString(mantissa, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+
+	return NewDecimal(unscaled, scale-exponent), nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+
+	return d.unscaled
+}
+
+// Add returns sum of decimals, encoding of d is kept.
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b := d.int(), other.int()
+	scale := d.scale
+
+	switch {
+	case d.scale < other.scale:
+		a = new(big.Int).Mul(a, pow10(other.scale-d.scale))
+		scale = other.scale
+	case d.scale > other.scale:
+		b = new(big.Int).Mul(b, pow10(d.scale-other.scale))
+	}
+
+	return Decimal{unscaled: new(big.Int).Add(a, b), scale: scale, encoding: d.encoding}
+}
+
+// Sub returns difference of decimals, encoding of d is kept.
+func (d Decimal) Sub(other Decimal) Decimal {
+	return d.Add(Decimal{unscaled: new(big.Int).Neg(other.int()), scale: other.scale})
+}
+
+// Cmp compares decimals and returns -1, 0 or +1.
+func (d Decimal) Cmp(other Decimal) int {
+	return d.Sub(other).int().Sign()
+}
+
+// String returns decimal representation of value.
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.int()).String()
+
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+
+	if d.int().Sign() < 0 {
+		return "-" + digits
+	}
+
+	return digits
+}
+
+// isNull returns true for Decimal scanned from NULL.
+func (d Decimal) isNull() bool {
+	return d.unscaled == nil
+}
+
+// Float64 returns nearest float64 value of decimal.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+
+	return f
+}
+
+// Scan implements sql.Scanner, integers, floats, decimal strings and fmt.Stringer values of drivers are supported.
+//
+//nolint:cyclop
+func (d *Decimal) Scan(src interface{}) error {
+	encoding := d.encoding
+
+	var err error
+
+	switch v := src.(type) {
+	case nil:
+		*d = Decimal{}
+	case int64:
+		*d = NewDecimal(big.NewInt(v), 0)
+	case uint64:
+		*d = NewDecimal(new(big.Int).SetUint64(v), 0)
+	case float64:
+		*d, err = ParseDecimal(strconv.FormatFloat(v, 'f', -1, 64))
+	case float32:
+		*d, err = ParseDecimal(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case []byte:
+		*d, err = ParseDecimal(string(v))
+	case string:
+		*d, err = ParseDecimal(v)
+	case *big.Int:
+		*d = NewDecimal(v, 0)
+	case driver.Valuer:
+		var value driver.Value
+
+		if value, err = v.Value(); err == nil {
+			err = d.Scan(value)
+		}
+	case fmt.Stringer:
+		*d, err = ParseDecimal(v.String())
+	default:
+		if n, ok := castInt64(v); ok {
+			*d = NewDecimal(big.NewInt(n), 0)
+		} else if u, ok := castUInt64(v); ok {
+			*d = NewDecimal(new(big.Int).SetUint64(u), 0)
+		} else {
+			err = fmt.Errorf("%w: %T", ErrInvalidDecimal, src)
+		}
+	}
+
+	d.encoding = encoding
+
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, decimal is encoded as number or string by encoding.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if d.encoding == DecimalEncodingString {
+		return []byte(strconv.Quote(d.String())), nil
+	}
+
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decimal is decoded from number or string.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+
+	encoding := DecimalEncodingNumber
+
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s, encoding = unquoted, DecimalEncodingString
+	}
+
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	d.encoding = encoding
+
+	return nil
+}
+
+// exactColumnPrefix prefix of alias of exact value of metric selected beside numeric value.
+const exactColumnPrefix = "statistica_exact_"
+
+// exactExpression returns expression of exact value of metric and true if dialect needs separate column
+// to scan it without loss of precision, numeric column of metric is kept for ORDER BY and HAVING.
+func (d Dialect) exactExpression(expression string) (string, bool) {
+	if d == DialectClickHouse {
+		// ClickHouse drivers scan Decimal and UInt64 aggregates into float64 or big types, string keeps all digits.
+		return fmt.Sprintf("toString(%s)", expression), true
+	}
+
+	return "", false
+}
+
+// applyExactDest sets Decimal destinations for columns of exact values of metrics.
+func (r *SQLRepository) applyExactDest(types []*sql.ColumnType, groups int, dest []interface{}) {
+	_, separate := r.dialect.exactExpression("")
+
+	for _, m := range r.metrics {
+		if !m.Exact {
+			continue
+		}
+
+		name := m.Name
+		if separate {
+			name = exactColumnPrefix + m.Name
+		}
+
+		for i := groups; i < len(types); i++ {
+			if types[i].Name() == name {
+				dest[i] = &Decimal{encoding: r.decimalEncoding}
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package statistica
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDecimal(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		value    string
+		expected string
+		err      bool
+	}{
+		{value: "123.45", expected: "123.45"},
+		{value: "-0.05", expected: "-0.05"},
+		{value: ".5", expected: "0.5"},
+		{value: "1.5e3", expected: "1500"},
+		{value: "15e-3", expected: "0.015"},
+		{value: "18446744073709551617", expected: "18446744073709551617"},
+		{value: "abc", err: true},
+		{value: "", err: true},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.value, func(t *testing.T) {
+			t.Parallel()
+
+			d, err := ParseDecimal(tc.value)
+			if tc.err {
+				require.True(t, errors.Is(err, ErrInvalidDecimal))
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, d.String())
+		})
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	t.Parallel()
+
+	a, err := ParseDecimal("9007199254740993.01")
+	require.NoError(t, err)
+
+	b, err := ParseDecimal("0.1")
+	require.NoError(t, err)
+
+	require.Equal(t, "9007199254740993.11", a.Add(b).String())
+	require.Equal(t, "9007199254740992.91", a.Sub(b).String())
+	require.Equal(t, 1, a.Cmp(b))
+	require.Equal(t, 0, b.Cmp(NewDecimal(big.NewInt(10), 2)))
+	require.Equal(t, "0.1", Decimal{}.Add(b).String())
+	require.Equal(t, 0.1, b.Float64())
+}
+
+func TestDecimal_Scan(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name     string
+		src      interface{}
+		expected string
+	}{
+		{name: "nil", src: nil, expected: "0"},
+		{name: "int64", src: int64(-7), expected: "-7"},
+		{name: "uint64", src: uint64(18446744073709551615), expected: "18446744073709551615"},
+		{name: "float64", src: 12.5, expected: "12.5"},
+		{name: "bytes", src: []byte("100.10"), expected: "100.10"},
+		{name: "string", src: "0.001", expected: "0.001"},
+		{name: "big int", src: big.NewInt(42), expected: "42"},
+		{name: "stringer", src: NewDecimal(big.NewInt(125), 1), expected: "12.5"},
+		{name: "uint32", src: uint32(3), expected: "3"},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var d Decimal
+
+			require.NoError(t, d.Scan(tc.src))
+			require.Equal(t, tc.expected, d.String())
+		})
+	}
+
+	var d Decimal
+	require.True(t, errors.Is(d.Scan(struct{}{}), ErrInvalidDecimal))
+
+	// non-finite values and nil numbers are NULL.
+	for _, src := range []interface{}{"nan", "-inf", []byte("+Inf"), math.NaN(), (*big.Int)(nil)} {
+		d = NewDecimal(big.NewInt(1), 0)
+
+		require.NoError(t, d.Scan(src))
+		require.True(t, d.isNull())
+	}
+
+	require.True(t, NewDecimal(nil, 2).isNull())
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	t.Parallel()
+
+	d, err := ParseDecimal("12345678901234567890.12")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(d)
+	require.NoError(t, err)
+	require.Equal(t, `12345678901234567890.12`, string(data))
+
+	d.encoding = DecimalEncodingString
+
+	data, err = json.Marshal(d)
+	require.NoError(t, err)
+	require.Equal(t, `"12345678901234567890.12"`, string(data))
+
+	var decoded []Decimal
+	require.NoError(t, json.Unmarshal([]byte(`[1.50, "2.25"]`), &decoded))
+	require.Equal(t, "1.50", decoded[0].String())
+	require.Equal(t, "2.25", decoded[1].String())
+}
+
+func TestSQLRepository_ExactMetrics(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}},
+		[]*Metric{
+			{Name: "total", Expression: "count(*)"},
+			{Name: "cost", Expression: "sum(price)", Exact: true},
+		},
+		DialectSQLRepositoryOption(DialectClickHouse),
+		DecimalEncodingSQLRepositoryOption(DecimalEncodingString),
+	)
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT geo_id, count(*) AS total,sum(price) AS cost,toString(sum(price)) AS statistica_exact_cost "+
+					"FROM test_table GROUP BY geo_id ORDER BY cost DESC") + "$",
+		).
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total", "cost", "statistica_exact_cost"}).
+			AddRow(int64(1), int64(2), 18446744073709551617.05, "18446744073709551617.05").
+			AddRow(int64(2), int64(1), math.NaN(), "nan"))
+
+	rows, err := r.Grouped(&ItemsRequest{
+		Groups: []string{"geo_id"},
+		SortBy: []*ItemsRequestOrder{{Key: "cost", Direction: "DESC"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, rows[0].ExactMetrics, 1)
+	require.Equal(t, "18446744073709551617.05", rows[0].ExactMetrics["cost"].String())
+	require.Equal(t, ValueNumber(18446744073709551617.05), rows[0].Metrics["cost"])
+	// non-finite value of metric without Nullable is zero.
+	require.Equal(t, ValueNumber(0), rows[1].Metrics["cost"])
+	require.Equal(t, "0", rows[1].ExactMetrics["cost"].String())
+
+	data, err := json.Marshal(rows[0].ExactMetrics)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"cost":"18446744073709551617.05"}`, string(data))
+
+	type costRow struct {
+		Cost Decimal `statistica:"cost"`
+		Text string  `statistica:"cost"`
+	}
+
+	decoded, err := DecodeItemRows[costRow](rows)
+	require.NoError(t, err)
+	require.Equal(t, "18446744073709551617.05", decoded[0].Cost.String())
+	require.Equal(t, "18446744073709551617.05", decoded[0].Text)
+
+	union := UnionItemsResponse(&ItemsResponse{Rows: rows}, &ItemsResponse{Rows: []*ItemRow{{
+		Dimensions:   rows[0].Dimensions,
+		Metrics:      map[string]ValueNumber{"cost": 0.95},
+		ExactMetrics: map[string]Decimal{"cost": NewDecimal(big.NewInt(95), 2)},
+	}}})
+	require.Equal(t, "18446744073709551618.00", union.Rows[0].ExactMetrics["cost"].String())
+}
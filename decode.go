@@ -0,0 +1,379 @@
+package statistica
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const decodeTagName = "statistica"
+
+var (
+	// ErrDecodeTarget returns when type for decoding is not struct or pointer to struct.
+	ErrDecodeTarget = errors.New("decode target must be struct or pointer to struct")
+	// ErrDecodeConversion returns when value could not be converted to type of field.
+	ErrDecodeConversion = errors.New("failed to convert value")
+)
+
+// FieldError this struct describes failed decoding of value to struct field.
+type FieldError struct {
+	// Row contains index of decoded row.
+	Row int
+
+	// Field contains name of struct field.
+	Field string
+
+	// Key contains name of dimension or metric.
+	Key string
+
+	// Value contains decoded value.
+	Value interface{}
+
+	// Err contains cause of error.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("row %d, field %s (%s): %v", e.Row, e.Field, e.Key, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError this struct contains all field errors of decoding.
+type DecodeError struct {
+	Errors []*FieldError
+}
+
+func (e *DecodeError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for i := range e.Errors {
+		messages = append(messages, e.Errors[i].Error())
+	}
+
+	return "failed to decode rows: " + strings.Join(messages, "; ")
+}
+
+func (e *DecodeError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for i := range e.Errors {
+		errs = append(errs, e.Errors[i])
+	}
+
+	return errs
+}
+
+// GroupedInto returns rows of Grouped decoded into structs T.
+func GroupedInto[T any](repository ReadRepository, req *ItemsRequest) ([]T, error) {
+	return GroupedIntoContext[T](context.Background(), repository, req)
+}
+
+// GroupedIntoContext returns rows of GroupedContext decoded into structs T.
+func GroupedIntoContext[T any](ctx context.Context, repository ReadRepository, req *ItemsRequest) ([]T, error) {
+	rows, err := repository.GroupedContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeItemRows[T](rows)
+}
+
+// DecodeItemRows decodes rows into structs T. Dimensions and metrics are mapped to fields
+// by tag `statistica:"name"` or by field name, fields with tag `statistica:"-"` are skipped.
+func DecodeItemRows[T any](rows []*ItemRow) ([]T, error) {
+	target := reflect.TypeOf((*T)(nil)).Elem()
+
+	structType := target
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s", ErrDecodeTarget, target)
+	}
+
+	fields := decodeFields(structType)
+	result := make([]T, 0, len(rows))
+	decodeErr := &DecodeError{}
+
+	for i, row := range rows {
+		item := reflect.New(structType).Elem()
+
+		for _, field := range fields {
+			value, ok := row.Dimensions[field.key]
+			if !ok {
+				value, ok = row.ExactMetrics[field.key]
+			}
+
+			if !ok {
+				var metric ValueNumber
+				if metric, ok = row.Metrics[field.key]; ok && metric.Valid() {
+					value = float64(metric)
+				}
+			}
+
+			if !ok {
+				continue
+			}
+
+			if err := assignValue(item.Field(field.index), value); err != nil {
+				decodeErr.Errors = append(decodeErr.Errors, &FieldError{
+					Row:   i,
+					Field: structType.Field(field.index).Name,
+					Key:   field.key,
+					Value: value,
+					Err:   err,
+				})
+			}
+		}
+
+		if target.Kind() == reflect.Ptr {
+			item = item.Addr()
+		}
+
+		result = append(result, item.Interface().(T))
+	}
+
+	if len(decodeErr.Errors) > 0 {
+		return nil, decodeErr
+	}
+
+	return result, nil
+}
+
+type decodeField struct {
+	index int
+	key   string
+}
+
+func decodeFields(structType reflect.Type) []decodeField {
+	fields := make([]decodeField, 0, structType.NumField())
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key := field.Name
+		if tag, ok := field.Tag.Lookup(decodeTagName); ok {
+			key = strings.Split(tag, ",")[0]
+		}
+
+		if key == "-" || key == "" {
+			continue
+		}
+
+		fields = append(fields, decodeField{index: i, key: key})
+	}
+
+	return fields
+}
+
+//nolint:cyclop
+func assignValue(field reflect.Value, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := assignValue(elem.Elem(), value); err != nil {
+			return err
+		}
+
+		field.Set(elem)
+
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(field.Type()) {
+		field.Set(v)
+
+		return nil
+	}
+
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+
+	if d, ok := value.(Decimal); ok {
+		value = d.String()
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(fmt.Sprint(value))
+
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt64(value)
+		if err == nil && field.OverflowInt(n) {
+			err = fmt.Errorf("%w: %v overflows %s", ErrDecodeConversion, value, field.Type())
+		}
+
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(n)
+
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := toUint64(value)
+		if err == nil && field.OverflowUint(u) {
+			err = fmt.Errorf("%w: %v overflows %s", ErrDecodeConversion, value, field.Type())
+		}
+
+		if err != nil {
+			return err
+		}
+
+		field.SetUint(u)
+
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat64(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetFloat(f)
+
+		return nil
+
+	case reflect.Bool:
+		b, err := toBool(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(b)
+
+		return nil
+	}
+
+	if field.Type() == reflect.TypeOf(time.Time{}) {
+		if s, ok := value.(string); ok {
+			t, ok := parseDate(s)
+			if !ok {
+				return fmt.Errorf("%w: %q to %s", ErrDecodeConversion, s, field.Type())
+			}
+
+			field.Set(reflect.ValueOf(t))
+
+			return nil
+		}
+	}
+
+	if v.Type().ConvertibleTo(field.Type()) {
+		field.Set(v.Convert(field.Type()))
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: %T to %s", ErrDecodeConversion, value, field.Type())
+}
+
+func toInt64(value interface{}) (int64, error) {
+	if n, ok := castInt64(value); ok {
+		return n, nil
+	}
+
+	if u, ok := castUInt64(value); ok {
+		if u > math.MaxInt64 {
+			return 0, fmt.Errorf("%w: %d overflows int64", ErrDecodeConversion, u)
+		}
+
+		return int64(u), nil
+	}
+
+	if f, ok := castFloat64(value); ok {
+		if f != math.Trunc(f) || f > math.MaxInt64 || f < math.MinInt64 {
+			return 0, fmt.Errorf("%w: %v is not integer", ErrDecodeConversion, f)
+		}
+
+		return int64(f), nil
+	}
+
+	if s, ok := value.(string); ok {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrDecodeConversion, err)
+		}
+
+		return n, nil
+	}
+
+	return 0, fmt.Errorf("%w: %T to integer", ErrDecodeConversion, value)
+}
+
+func toUint64(value interface{}) (uint64, error) {
+	if u, ok := castUInt64(value); ok {
+		return u, nil
+	}
+
+	n, err := toInt64(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if n < 0 {
+		return 0, fmt.Errorf("%w: %d is negative", ErrDecodeConversion, n)
+	}
+
+	return uint64(n), nil
+}
+
+func toFloat64(value interface{}) (float64, error) {
+	if f, ok := castFloat64(value); ok {
+		return f, nil
+	}
+
+	if n, ok := castInt64(value); ok {
+		return float64(n), nil
+	}
+
+	if u, ok := castUInt64(value); ok {
+		return float64(u), nil
+	}
+
+	if s, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrDecodeConversion, err)
+		}
+
+		return f, nil
+	}
+
+	return 0, fmt.Errorf("%w: %T to float", ErrDecodeConversion, value)
+}
+
+func toBool(value interface{}) (bool, error) {
+	if s, ok := value.(string); ok {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrDecodeConversion, err)
+		}
+
+		return b, nil
+	}
+
+	n, err := toInt64(value)
+	if err != nil || (n != 0 && n != 1) {
+		return false, fmt.Errorf("%w: %v to bool", ErrDecodeConversion, value)
+	}
+
+	return n == 1, nil
+}
@@ -0,0 +1,71 @@
+package statistica
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+type testDecodeRow struct {
+	UserID int64   `statistica:"user_id"`
+	GeoID  *uint32 `statistica:"geo_id"`
+	Total  int     `statistica:"total"`
+	Cost   float64
+	Skip   string `statistica:"-"`
+}
+
+func TestGroupedInto(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "geo_id", "total"}).
+			AddRow(int64(1), int64(10), int64(100)).
+			AddRow(int64(2), nil, int64(200)))
+
+	rows, err := GroupedInto[testDecodeRow](testRepository(t, db), &ItemsRequest{
+		Groups: []string{"user_id", "geo_id"},
+	})
+	require.NoError(t, err)
+
+	geo := uint32(10)
+	require.Equal(t, []testDecodeRow{
+		{UserID: 1, GeoID: &geo, Total: 100},
+		{UserID: 2, Total: 200},
+	}, rows)
+}
+
+func TestDecodeItemRows(t *testing.T) {
+	t.Parallel()
+
+	rows, err := DecodeItemRows[*testDecodeRow]([]*ItemRow{
+		{
+			Dimensions: map[string]interface{}{"user_id": []byte("5"), "Skip": "value"},
+			Metrics:    map[string]ValueNumber{"total": 3, "Cost": 1.5},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*testDecodeRow{{UserID: 5, Total: 3, Cost: 1.5}}, rows)
+
+	_, err = DecodeItemRows[testDecodeRow]([]*ItemRow{
+		{
+			Dimensions: map[string]interface{}{"user_id": "abc", "geo_id": int64(-1)},
+			Metrics:    map[string]ValueNumber{"total": 1.5},
+		},
+	})
+	require.True(t, errors.Is(err, ErrDecodeConversion))
+
+	var decodeErr *DecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	require.Len(t, decodeErr.Errors, 3)
+	require.Equal(t, "UserID", decodeErr.Errors[0].Field)
+	require.Equal(t, "geo_id", decodeErr.Errors[1].Key)
+	require.Equal(t, "Total", decodeErr.Errors[2].Field)
+
+	_, err = DecodeItemRows[int](nil)
+	require.True(t, errors.Is(err, ErrDecodeTarget))
+}
@@ -0,0 +1,146 @@
+package statistica
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect special type for represent SQL dialect of connection.
+type Dialect string
+
+const (
+	// DialectGeneric common SQL without dialect specific features.
+	DialectGeneric Dialect = ""
+	// DialectClickHouse ClickHouse SQL dialect.
+	DialectClickHouse Dialect = "clickhouse"
+	// DialectMySQL MySQL SQL dialect.
+	DialectMySQL Dialect = "mysql"
+	// DialectSQLite SQLite SQL dialect.
+	DialectSQLite Dialect = "sqlite"
+	// DialectPostgres PostgreSQL SQL dialect, placeholders are rendered as $1, $2.
+	DialectPostgres Dialect = "postgres"
+)
+
+// DialectSQLRepositoryOption sets SQL dialect of connection.
+func DialectSQLRepositoryOption(dialect Dialect) SQLRepositoryOption {
+	return func(repository *SQLRepository) {
+		repository.dialect = dialect
+	}
+}
+
+// System returns name of database system.
+func (d Dialect) System() string {
+	switch d {
+	case DialectGeneric:
+		return "other_sql"
+	case DialectPostgres:
+		return "postgresql"
+	}
+
+	return string(d)
+}
+
+// explainPrefix returns statement which shows plan of query.
+func (d Dialect) explainPrefix() string {
+	if d == DialectSQLite {
+		return "EXPLAIN QUERY PLAN "
+	}
+
+	return "EXPLAIN "
+}
+
+// limit returns LIMIT clause of dialect.
+func (d Dialect) limit(limit, offset int) string {
+	if offset <= 0 {
+		return fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	if d == DialectPostgres {
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	}
+
+	return fmt.Sprintf(" LIMIT %d, %d", offset, limit)
+}
+
+// rebind returns query with placeholders of dialect, placeholders in quoted strings are kept.
+func (d Dialect) rebind(query string) string {
+	if d != DialectPostgres {
+		return query
+	}
+
+	var (
+		b      strings.Builder
+		n      int
+		quoted bool
+	)
+
+	for _, c := range query {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case c == '?' && !quoted:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+
+			continue
+		}
+
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// like returns LIKE condition of key with placeholder of pattern escaped by escapeLike.
+func (d Dialect) like(key string) string {
+	switch d {
+	case DialectClickHouse, DialectMySQL:
+		// backslash is default escape character of LIKE.
+		return fmt.Sprintf("%s LIKE ?", key)
+	}
+
+	return fmt.Sprintf("%s LIKE ? ESCAPE '\\'", key)
+}
+
+// escapeLike returns value with escaped wildcards of LIKE pattern.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// metricExpression returns sql expression of metric kind, false if dialect has no native support of kind.
+func (d Dialect) metricExpression(m *Metric) (string, bool) {
+	quantile := strconv.FormatFloat(m.quantile(), 'f', -1, 64)
+
+	switch m.Kind {
+	case MetricKindQuantile, MetricKindMedian:
+		switch d {
+		case DialectClickHouse:
+			if m.Kind == MetricKindMedian {
+				return fmt.Sprintf("median(%s)", m.Column), true
+			}
+
+			return fmt.Sprintf("quantile(%s)(%s)", quantile, m.Column), true
+		case DialectPostgres:
+			return fmt.Sprintf("percentile_cont(%s) WITHIN GROUP (ORDER BY %s)", quantile, m.Column), true
+		}
+
+		return "", false
+
+	case MetricKindDistinct:
+		if d == DialectClickHouse {
+			return fmt.Sprintf("uniqExact(%s)", m.Column), true
+		}
+
+		return fmt.Sprintf("COUNT(DISTINCT %s)", m.Column), true
+
+	case MetricKindApproxDistinct:
+		if d == DialectClickHouse {
+			return fmt.Sprintf("uniq(%s)", m.Column), true
+		}
+
+		return fmt.Sprintf("COUNT(DISTINCT %s)", m.Column), true
+	}
+
+	return m.Expression, true
+}
@@ -0,0 +1,20 @@
+package statistica
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect_Postgres(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t,
+		"SELECT a FROM t WHERE a IN ($1,$2) AND b LIKE '%?%' AND c > $3",
+		DialectPostgres.rebind("SELECT a FROM t WHERE a IN (?,?) AND b LIKE '%?%' AND c > ?"),
+	)
+	require.Equal(t, "a = ?", DialectMySQL.rebind("a = ?"))
+	require.Equal(t, " LIMIT 10 OFFSET 20", DialectPostgres.limit(10, 20))
+	require.Equal(t, " LIMIT 20, 10", DialectMySQL.limit(10, 20))
+	require.Equal(t, " LIMIT 10", DialectPostgres.limit(10, 0))
+}
@@ -0,0 +1,285 @@
+package statistica
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrInvalidDictionary returns when dictionary source has invalid format.
+var ErrInvalidDictionary = errors.New("invalid dictionary")
+
+// ErrNoDictionary returns when request filters dimension without dictionary by labels.
+var ErrNoDictionary = errors.New("dimension has no dictionary")
+
+// Dictionary common interface of human-readable labels of dimension values.
+type Dictionary interface {
+	// Label returns label of dimension value.
+	Label(key interface{}) (string, bool)
+	// Keys returns dimension values with label.
+	Keys(label string) []interface{}
+}
+
+// StaticDictionary implementation of Dictionary, contains labels by string representation of dimension value.
+type StaticDictionary map[string]string
+
+// Label returns label of dimension value.
+func (d StaticDictionary) Label(key interface{}) (string, bool) {
+	label, ok := d[dictionaryKey(key)]
+
+	return label, ok
+}
+
+// Keys returns dimension values with label.
+func (d StaticDictionary) Keys(label string) []interface{} {
+	keys := make([]string, 0)
+
+	for key := range d {
+		if d[key] == label {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	result := make([]interface{}, len(keys))
+	for i := range keys {
+		result[i] = keys[i]
+	}
+
+	return result
+}
+
+func dictionaryKey(key interface{}) string {
+	if b, ok := key.([]byte); ok {
+		return string(b)
+	}
+
+	return fmt.Sprint(key)
+}
+
+// NewCSVDictionary returns StaticDictionary loaded from CSV file with key and label columns.
+func NewCSVDictionary(path string) (StaticDictionary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dictionary file: %w", err)
+	}
+	defer f.Close()
+
+	return ReadCSVDictionary(f)
+}
+
+// ReadCSVDictionary returns StaticDictionary read from CSV with key and label columns.
+func ReadCSVDictionary(r io.Reader) (StaticDictionary, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+
+	dictionary := make(StaticDictionary)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDictionary, err)
+		}
+
+		dictionary[record[0]] = record[1]
+	}
+
+	return dictionary, nil
+}
+
+// SQLDictionary implementation of Dictionary loaded by SQL query which returns key and label columns.
+type SQLDictionary struct {
+	conn     *sql.DB
+	query    string
+	interval time.Duration
+
+	mu         sync.RWMutex
+	dictionary StaticDictionary
+	err        error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSQLDictionary returns loaded SQLDictionary, dictionary is refreshed on interval until ctx is done
+// or Close is called. Zero interval disables refresh.
+func NewSQLDictionary(ctx context.Context, conn *sql.DB, query string, interval time.Duration) (*SQLDictionary, error) {
+	d := &SQLDictionary{
+		conn:       conn,
+		query:      query,
+		interval:   interval,
+		dictionary: make(StaticDictionary),
+		done:       make(chan struct{}),
+	}
+
+	if err := d.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, d.cancel = context.WithCancel(ctx)
+
+	go d.run(ctx)
+
+	return d, nil
+}
+
+// Label returns label of dimension value.
+func (d *SQLDictionary) Label(key interface{}) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.dictionary.Label(key)
+}
+
+// Keys returns dimension values with label.
+func (d *SQLDictionary) Keys(label string) []interface{} {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.dictionary.Keys(label)
+}
+
+// Err returns error of last refresh.
+func (d *SQLDictionary) Err() error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.err
+}
+
+// Refresh reloads dictionary, previous labels are kept if query failed.
+func (d *SQLDictionary) Refresh(ctx context.Context) error {
+	dictionary, err := d.load(ctx)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.err = err
+	if err == nil {
+		d.dictionary = dictionary
+	}
+
+	return err
+}
+
+// Close stops refresh of dictionary.
+func (d *SQLDictionary) Close() {
+	d.cancel()
+	<-d.done
+}
+
+func (d *SQLDictionary) run(ctx context.Context) {
+	defer close(d.done)
+
+	if d.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = d.Refresh(ctx)
+		}
+	}
+}
+
+func (d *SQLDictionary) load(ctx context.Context) (StaticDictionary, error) {
+	rows, err := d.conn.QueryContext(ctx, d.query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exec query: %w, query: %s", err, d.query)
+	}
+	defer rows.Close()
+
+	dictionary := make(StaticDictionary)
+
+	for rows.Next() {
+		var key, label interface{}
+
+		if err := rows.Scan(&key, &label); err != nil {
+			return nil, err
+		}
+
+		dictionary[dictionaryKey(key)] = dictionaryKey(label)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dictionary, nil
+}
+
+// resolveLabels returns copy of request where label filters are replaced by filters of dimension values.
+func (r *SQLRepository) resolveLabels(req *ItemsRequest) (*ItemsRequest, error) {
+	resolved := *req
+	resolved.Filters = make([]*ItemsRequestFilter, 0, len(req.Filters))
+
+	for _, filter := range req.Filters {
+		dim, exists := r.getDimension(DimensionKey(filter.Key))
+		if !exists || filter.Condition != CondLabel {
+			resolved.Filters = append(resolved.Filters, filter)
+
+			continue
+		}
+
+		if dim.Dictionary == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNoDictionary, filter.Key)
+		}
+
+		keys := make([]interface{}, 0, len(filter.Values))
+		for i := range filter.Values {
+			keys = append(keys, dim.Dictionary.Keys(dictionaryKey(filter.Values[i]))...)
+		}
+
+		condition := CondEq
+		if len(keys) == 0 {
+			// keeps label condition without values which matches nothing.
+			condition = CondLabel
+		}
+
+		resolved.Filters = append(resolved.Filters, &ItemsRequestFilter{
+			Key:       filter.Key,
+			Values:    keys,
+			Condition: condition,
+		})
+	}
+
+	return &resolved, nil
+}
+
+// dimensionLabel returns label of dimension value from dictionary of dimension,
+// Dimension.NullLabel is label of NULL value.
+func (r *SQLRepository) dimensionLabel(key string, value interface{}) (string, bool) {
+	dim, exists := r.getDimension(DimensionKey(key))
+	if !exists {
+		return "", false
+	}
+
+	value = driverValue(value)
+	if value == nil {
+		return dim.NullLabel, dim.NullLabel != ""
+	}
+
+	if dim.Dictionary == nil {
+		return "", false
+	}
+
+	return dim.Dictionary.Label(value)
+}
@@ -0,0 +1,148 @@
+package statistica
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticDictionary(t *testing.T) {
+	t.Parallel()
+
+	d := StaticDictionary{"100": "impression", "101": "click", "102": "click"}
+
+	label, ok := d.Label(int64(100))
+	require.True(t, ok)
+	require.Equal(t, "impression", label)
+
+	label, ok = d.Label([]byte("101"))
+	require.True(t, ok)
+	require.Equal(t, "click", label)
+
+	_, ok = d.Label(103)
+	require.False(t, ok)
+
+	require.Equal(t, []interface{}{"101", "102"}, d.Keys("click"))
+	require.Empty(t, d.Keys("unknown"))
+}
+
+func TestReadCSVDictionary(t *testing.T) {
+	t.Parallel()
+
+	d, err := ReadCSVDictionary(strings.NewReader("100,impression\n101,click\n"))
+	require.NoError(t, err)
+	require.Equal(t, StaticDictionary{"100": "impression", "101": "click"}, d)
+
+	_, err = ReadCSVDictionary(strings.NewReader("100,impression,extra\n"))
+	require.True(t, errors.Is(err, ErrInvalidDictionary))
+}
+
+func TestSQLDictionary(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	query := "SELECT id, name FROM event_types"
+
+	mock.ExpectQuery("^" + regexp.QuoteMeta(query) + "$").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(100), "impression"))
+	mock.ExpectQuery("^" + regexp.QuoteMeta(query) + "$").
+		WillReturnError(errors.New("connection lost"))
+	mock.ExpectQuery("^" + regexp.QuoteMeta(query) + "$").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(100), "view"))
+
+	d, err := NewSQLDictionary(context.Background(), db, query, time.Hour)
+	require.NoError(t, err)
+
+	defer d.Close()
+
+	label, _ := d.Label(100)
+	require.Equal(t, "impression", label)
+
+	require.Error(t, d.Refresh(context.Background()))
+	require.Error(t, d.Err())
+
+	label, _ = d.Label(100)
+	require.Equal(t, "impression", label)
+
+	require.NoError(t, d.Refresh(context.Background()))
+	require.NoError(t, d.Err())
+
+	label, _ = d.Label(100)
+	require.Equal(t, "view", label)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepository_Labels(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{
+			{
+				Name:       "event_type",
+				Expression: "etype",
+				Type:       DimensionTypeInt,
+				Dictionary: StaticDictionary{"100": "impression", "101": "click"},
+			},
+			{Name: "geo_id", Expression: "geo_id"},
+		},
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+	)
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT etype, count(*) AS total FROM test_table WHERE etype IN (?) GROUP BY etype") + "$",
+		).
+		WithArgs(int64(101)).
+		WillReturnRows(sqlmock.NewRows([]string{"etype", "total"}).AddRow(int64(101), int64(3)))
+
+	rows, err := r.Grouped(&ItemsRequest{
+		Groups:  []string{"event_type"},
+		Filters: []*ItemsRequestFilter{{Key: "event_type", Condition: CondLabel, Values: []interface{}{"click"}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"event_type": "click"}, rows[0].Labels)
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT etype, count(*) AS total FROM test_table WHERE 1 = 0 GROUP BY etype") + "$",
+		).
+		WillReturnRows(sqlmock.NewRows([]string{"etype", "total"}))
+
+	values, err := r.Values(&ItemsRequest{
+		Groups:  []string{"event_type"},
+		Filters: []*ItemsRequestFilter{{Key: "event_type", Condition: CondLabel, Values: []interface{}{"unknown"}}},
+	})
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	mock.
+		ExpectQuery("^" + regexp.QuoteMeta("SELECT etype, count(*) AS total FROM test_table GROUP BY etype") + "$").
+		WillReturnRows(sqlmock.NewRows([]string{"etype", "total"}).
+			AddRow(int64(100), int64(5)).
+			AddRow(int64(103), int64(1)))
+
+	values, err = r.Values(&ItemsRequest{Groups: []string{"event_type"}})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"impression"}, values[0].Label)
+	require.Nil(t, values[1].Label)
+
+	_, err = r.Grouped(&ItemsRequest{
+		Groups:  []string{"event_type"},
+		Filters: []*ItemsRequestFilter{{Key: "geo_id", Condition: CondLabel, Values: []interface{}{"1"}}},
+	})
+	require.True(t, errors.Is(err, ErrNoDictionary))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,270 @@
+package statistica
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ErrInvalidFilterValue returns when filter value does not match type of dimension.
+var ErrInvalidFilterValue = errors.New("invalid filter value")
+
+// DimensionType special type for represent type of dimension values.
+type DimensionType string
+
+const (
+	// DimensionTypeString dimension with string values.
+	DimensionTypeString DimensionType = "string"
+	// DimensionTypeInt dimension with integer values.
+	DimensionTypeInt DimensionType = "int"
+	// DimensionTypeFloat dimension with float values.
+	DimensionTypeFloat DimensionType = "float"
+	// DimensionTypeBool dimension with bool values.
+	DimensionTypeBool DimensionType = "bool"
+	// DimensionTypeDate dimension with date values, formatted by Dimension.Format.
+	DimensionTypeDate DimensionType = "date"
+	// DimensionTypeDateTime dimension with date and time values, formatted by Dimension.Format.
+	DimensionTypeDateTime DimensionType = "datetime"
+	// DimensionTypeEnum dimension with string values from Dimension.Enum.
+	DimensionTypeEnum DimensionType = "enum"
+	// DimensionTypeIP dimension with IPv4 or IPv6 address values.
+	DimensionTypeIP DimensionType = "ip"
+)
+
+const (
+	defaultDateFormat     = "2006-01-02"
+	defaultDateTimeFormat = "2006-01-02 15:04:05"
+)
+
+// FilterValueError this struct describes filter value which does not match type of dimension.
+type FilterValueError struct {
+	// Key contains name of dimension.
+	Key DimensionKey
+
+	// Type contains type of dimension.
+	Type DimensionType
+
+	// Value contains rejected value.
+	Value interface{}
+}
+
+func (e *FilterValueError) Error() string {
+	return fmt.Sprintf("%s: %v for %s dimension %q", ErrInvalidFilterValue, e.Value, e.Type, e.Key)
+}
+
+func (e *FilterValueError) Unwrap() error {
+	return ErrInvalidFilterValue
+}
+
+// Coerce returns value converted to type of dimension, untyped dimension returns value as is.
+//
+//nolint:cyclop
+func (d *Dimension) Coerce(value interface{}) (interface{}, error) {
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+
+	var (
+		coerced interface{}
+		err     error
+	)
+
+	switch d.Type {
+	case DimensionTypeString:
+		coerced = fmt.Sprint(value)
+	case DimensionTypeInt:
+		coerced, err = toInt64(value)
+	case DimensionTypeFloat:
+		coerced, err = toFloat64(value)
+	case DimensionTypeBool:
+		coerced, err = toBool(value)
+	case DimensionTypeDate, DimensionTypeDateTime:
+		t, ok := parseDate(value)
+		if !ok {
+			t, ok = d.parseFormat(value)
+		}
+
+		if !ok {
+			err = ErrInvalidFilterValue
+		}
+
+		coerced = t.Format(d.layout())
+	case DimensionTypeEnum:
+		coerced = fmt.Sprint(value)
+		if !d.inEnum(coerced.(string)) {
+			err = ErrInvalidFilterValue
+		}
+	case DimensionTypeIP:
+		ip := net.ParseIP(fmt.Sprint(value))
+		if ip == nil {
+			err = ErrInvalidFilterValue
+		}
+
+		coerced = ip.String()
+	default:
+		coerced = value
+	}
+
+	if err != nil {
+		return nil, &FilterValueError{Key: d.Name, Type: d.Type, Value: value}
+	}
+
+	return coerced, nil
+}
+
+// Normalize returns scanned value converted to type of dimension,
+// value which could not be converted is returned as is.
+func (d *Dimension) Normalize(value interface{}) interface{} {
+	if d.Type == "" {
+		return value
+	}
+
+	value = driverValue(value)
+	if value == nil {
+		return nil
+	}
+
+	switch d.Type {
+	case DimensionTypeDate, DimensionTypeDateTime:
+		if t, ok := value.(time.Time); ok {
+			return t.Format(d.layout())
+		}
+	case DimensionTypeIP:
+		if ip, ok := value.(net.IP); ok {
+			return ip.String()
+		}
+
+		if b, ok := value.([]byte); ok {
+			// text columns are scanned as bytes too, so binary form is used only if bytes are not text of address.
+			if ip := net.ParseIP(string(b)); ip != nil {
+				return ip.String()
+			}
+
+			if len(b) == net.IPv4len || len(b) == net.IPv6len {
+				return net.IP(b).String()
+			}
+		}
+	}
+
+	coerced, err := d.Coerce(value)
+	if err != nil {
+		return value
+	}
+
+	return coerced
+}
+
+// driverValue returns value of nullable types like sql.NullInt64, nil if value is null.
+func driverValue(value interface{}) interface{} {
+	valuer, ok := value.(driver.Valuer)
+	if !ok {
+		return value
+	}
+
+	v, err := valuer.Value()
+	if err != nil {
+		return value
+	}
+
+	return v
+}
+
+func (d *Dimension) layout() string {
+	if d.Format != "" {
+		return d.Format
+	}
+
+	if d.Type == DimensionTypeDateTime {
+		return defaultDateTimeFormat
+	}
+
+	return defaultDateFormat
+}
+
+func (d *Dimension) parseFormat(value interface{}) (time.Time, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(d.layout(), s)
+
+	return t, err == nil
+}
+
+func (d *Dimension) inEnum(value string) bool {
+	for i := range d.Enum {
+		if d.Enum[i] == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// coerceFilters returns copy of request with filter values converted to types of dimensions.
+func (r *SQLRepository) coerceFilters(req *ItemsRequest) (*ItemsRequest, error) {
+	coerced := *req
+	coerced.Filters = make([]*ItemsRequestFilter, 0, len(req.Filters))
+
+	for _, filter := range req.Filters {
+		dim, exists := r.getDimension(DimensionKey(filter.Key))
+		if !exists || dim.Type == "" || !coercibleCondition(filter.Condition) {
+			coerced.Filters = append(coerced.Filters, filter)
+
+			continue
+		}
+
+		values := make([]interface{}, len(filter.Values))
+
+		for i := range filter.Values {
+			if filter.Values[i] == nil {
+				// nil value matches NULL values of dimension.
+				continue
+			}
+
+			value, err := dim.Coerce(filter.Values[i])
+			if err != nil {
+				return nil, err
+			}
+
+			values[i] = value
+		}
+
+		coerced.Filters = append(coerced.Filters, &ItemsRequestFilter{
+			Key:       filter.Key,
+			Values:    values,
+			Condition: filter.Condition,
+		})
+	}
+
+	return &coerced, nil
+}
+
+func coercibleCondition(condition Condition) bool {
+	switch condition {
+	case CondLike, CondPrefix, CondLabel, CondIsNull, CondIsNotNull:
+		return false
+	}
+
+	return true
+}
+
+// normalizeDimension returns scanned value of dimension converted to type of dimension,
+// NULL value is returned as nil.
+func (r *SQLRepository) normalizeDimension(key string, value interface{}) interface{} {
+	value = driverValue(value)
+
+	dim, exists := r.getDimension(DimensionKey(key))
+	if exists && dim.Type != "" {
+		return dim.Normalize(value)
+	}
+
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+
+	return value
+}
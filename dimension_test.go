@@ -0,0 +1,127 @@
+package statistica
+
+import (
+	"database/sql"
+	"errors"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDimension_Coerce(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name      string
+		dimension *Dimension
+		value     interface{}
+		expected  interface{}
+		err       bool
+	}{
+		{name: "untyped", dimension: &Dimension{}, value: 1, expected: 1},
+		{name: "string", dimension: &Dimension{Type: DimensionTypeString}, value: 10, expected: "10"},
+		{name: "int", dimension: &Dimension{Type: DimensionTypeInt}, value: "101", expected: int64(101)},
+		{name: "int invalid", dimension: &Dimension{Type: DimensionTypeInt}, value: "abc", err: true},
+		{name: "float", dimension: &Dimension{Type: DimensionTypeFloat}, value: "1.5", expected: 1.5},
+		{name: "bool", dimension: &Dimension{Type: DimensionTypeBool}, value: int64(1), expected: true},
+		{name: "date", dimension: &Dimension{Type: DimensionTypeDate}, value: "2022-10-01", expected: "2022-10-01"},
+		{
+			name:      "date from time",
+			dimension: &Dimension{Type: DimensionTypeDate},
+			value:     time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC),
+			expected:  "2022-10-01",
+		},
+		{
+			name:      "datetime with format",
+			dimension: &Dimension{Type: DimensionTypeDateTime, Format: "02.01.2006 15:04"},
+			value:     "01.10.2022 12:30",
+			expected:  "01.10.2022 12:30",
+		},
+		{name: "date invalid", dimension: &Dimension{Type: DimensionTypeDate}, value: "yesterday", err: true},
+		{name: "enum", dimension: &Dimension{Type: DimensionTypeEnum, Enum: []string{"a", "b"}}, value: "b", expected: "b"},
+		{name: "enum invalid", dimension: &Dimension{Type: DimensionTypeEnum, Enum: []string{"a", "b"}}, value: "c", err: true},
+		{name: "ip", dimension: &Dimension{Type: DimensionTypeIP}, value: "::ffff:127.0.0.1", expected: "127.0.0.1"},
+		{name: "ip invalid", dimension: &Dimension{Type: DimensionTypeIP}, value: "localhost", err: true},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			value, err := tc.dimension.Coerce(tc.value)
+			if tc.err {
+				require.True(t, errors.Is(err, ErrInvalidFilterValue))
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestDimension_Normalize(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "2022-10-01",
+		(&Dimension{Type: DimensionTypeDate}).Normalize(time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)))
+	require.Equal(t, "10.0.0.1", (&Dimension{Type: DimensionTypeIP}).Normalize(net.IPv4(10, 0, 0, 1)))
+	require.Equal(t, "10.0.0.1", (&Dimension{Type: DimensionTypeIP}).Normalize([]byte{10, 0, 0, 1}))
+	require.Equal(t, "1::2", (&Dimension{Type: DimensionTypeIP}).Normalize([]byte("1::2")))
+	require.Equal(t, "2001:db8::a:b:cd", (&Dimension{Type: DimensionTypeIP}).Normalize([]byte("2001:db8::a:b:cd")))
+	require.Equal(t, int64(100), (&Dimension{Type: DimensionTypeInt}).Normalize([]byte("100")))
+	require.Equal(t, "raw", (&Dimension{Type: DimensionTypeInt}).Normalize("raw"))
+	require.Nil(t, (&Dimension{Type: DimensionTypeInt}).Normalize(nil))
+	require.Equal(t, int64(7), (&Dimension{Type: DimensionTypeInt}).Normalize(sql.NullInt64{Int64: 7, Valid: true}))
+	require.Nil(t, (&Dimension{Type: DimensionTypeInt}).Normalize(sql.NullInt64{}))
+}
+
+func TestSQLRepository_TypedFilters(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{
+			{Name: "event_type", Expression: "etype", Type: DimensionTypeInt},
+			{Name: "created", Expression: "created", Type: DimensionTypeDate},
+		},
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+	)
+
+	mock.
+		ExpectQuery(
+			"^"+regexp.QuoteMeta(
+				"SELECT created, count(*) AS total FROM test_table "+
+					"WHERE etype IN (?,?) AND created >= ? GROUP BY created")+"$",
+		).
+		WithArgs(int64(100), int64(101), "2022-10-01").
+		WillReturnRows(sqlmock.NewRows([]string{"created", "total"}).
+			AddRow(time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC), int64(3)))
+
+	rows, err := r.Grouped(&ItemsRequest{
+		Groups: []string{"created"},
+		Filters: []*ItemsRequestFilter{
+			{Key: "event_type", Values: []interface{}{"100", 101.0}},
+			{Key: "created", Condition: CondGreaterOrEq, Values: []interface{}{time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "2022-10-01", rows[0].Dimensions["created"])
+
+	_, err = r.Grouped(&ItemsRequest{
+		Filters: []*ItemsRequestFilter{{Key: "event_type", Values: []interface{}{"click"}}},
+	})
+
+	var valueErr *FilterValueError
+	require.True(t, errors.As(err, &valueErr))
+	require.Equal(t, DimensionKey("event_type"), valueErr.Key)
+}
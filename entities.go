@@ -1,8 +1,51 @@
 package statistica
 
+import (
+	"encoding/json"
+	"math"
+)
+
 // ValueNumber special type for representation number value.
 type ValueNumber float64
 
+// NullValueNumber returns ValueNumber which represents NULL.
+func NullValueNumber() ValueNumber {
+	return ValueNumber(math.NaN())
+}
+
+// Valid returns false if value represents NULL, NaN and infinite values represent NULL.
+func (v ValueNumber) Valid() bool {
+	return !math.IsNaN(float64(v)) && !math.IsInf(float64(v), 0)
+}
+
+// MarshalJSON implements json.Marshaler, value which represents NULL is encoded as null.
+// Values of metrics without Metric.Nullable are zero instead of NULL, so they are encoded as numbers.
+func (v ValueNumber) MarshalJSON() ([]byte, error) {
+	if !v.Valid() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(float64(v))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, null is decoded as NullValueNumber.
+func (v *ValueNumber) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*v = NullValueNumber()
+
+		return nil
+	}
+
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	*v = ValueNumber(f)
+
+	return nil
+}
+
 // ValueResponse this struct represents value response.
 type ValueResponse struct {
 	// Name value from query.
@@ -13,6 +56,9 @@ type ValueResponse struct {
 
 	//	Count size by value Key.
 	Count ValueNumber `json:"count"`
+
+	// Label contains labels of Key from dictionaries of dimensions, nil if value has no label.
+	Label []interface{} `json:"label,omitempty"`
 }
 
 // ValuesResponse this struct represents values response.
@@ -24,12 +70,25 @@ type ValuesResponse struct {
 type ItemsResponse struct {
 	Rows  []*ItemRow
 	Total ValueNumber
+
+	// Summary contains values of metrics across all groups of request, it is filled by Page.
+	Summary *ItemRow `json:",omitempty"`
+
+	// NextCursor contains cursor of next page of request with Cursor, empty for last page.
+	NextCursor string `json:",omitempty"`
 }
 
 // ItemRow this struct represent one row of statistic.
 type ItemRow struct {
 	Dimensions map[string]interface{}
 	Metrics    map[string]ValueNumber
+	Labels     map[string]string
+
+	// ExactMetrics contains values of metrics with Metric.Exact without loss of precision.
+	ExactMetrics map[string]Decimal `json:",omitempty"`
+
+	// Type contains type of synthetic row, empty for row of group.
+	Type RowType `json:",omitempty"`
 }
 
 // ItemsRequestFilter this struct represents request filter.
@@ -47,6 +106,9 @@ type ItemsRequestOrder struct {
 
 // ItemsRequest this struct represents request query.
 type ItemsRequest struct {
+	// Cube contains name of cube in Catalog, it is ignored by repository.
+	Cube string
+
 	Limit  int
 	Offset int
 
@@ -54,6 +116,24 @@ type ItemsRequest struct {
 	Groups  []string
 	Metrics []string
 	Filters []*ItemsRequestFilter
+
+	// Top enables top groups by metric with rows of other groups and total in Grouped.
+	Top *TopRequest
+
+	// Totals enables rows of subtotals of each level of groups and row of grand total in Grouped, it excludes Top.
+	Totals bool
+
+	// Windows contains window metrics computed over rows of Grouped, e.g. running totals or rank.
+	Windows []*WindowMetric
+
+	// Search filters rows by values or labels of groups matched by term, Values are ranked by count.
+	Search *SearchRequest
+
+	// Cursor enables keyset pagination of Grouped, Offset is ignored for page after cursor.
+	Cursor *CursorRequest
+
+	// after contains decoded values of Cursor, they are set by seekRequest.
+	after []interface{}
 }
 
 // Metric this struct describe metrics model.
@@ -66,6 +146,42 @@ type Metric struct {
 
 	// Expression contains sql expression for computed statistic metric.
 	Expression string
+
+	// Kind contains kind of metric rendered by dialect, Expression is used for metric without kind.
+	Kind MetricKind
+
+	// Column contains sql expression of column for metric with kind.
+	Column string
+
+	// Quantile contains level of quantile from 0 to 1 for MetricKindQuantile.
+	Quantile float64
+
+	// Merge contains how values of several groups are merged, e.g. into row of other groups.
+	Merge MetricMerge
+
+	// Nullable enables NULL values of metric, NULL, NaN and infinite values are returned as NullValueNumber
+	// and encoded as JSON null. Values of metric without Nullable are zero instead of NULL.
+	Nullable bool
+
+	// Exact enables scanning of values into Decimal without loss of precision, e.g. for sums of money.
+	// Values are returned in ItemRow.ExactMetrics.
+	Exact bool
+
+	// Unit contains unit of displayed value.
+	Unit MetricUnit
+
+	// Currency contains ISO 4217 code of currency for MetricUnitCurrency, e.g. "USD".
+	Currency string
+
+	// Scale contains multiplier of value to displayed unit, e.g. 0.01 for cents, zero means 1.
+	Scale float64
+
+	// Precision contains count of digits after decimal point of displayed value, e.g. MetricPrecision(2),
+	// nil precision uses the smallest count of digits necessary.
+	Precision *int
+
+	// Direction contains which change of value is better.
+	Direction MetricDirection
 }
 
 // DimensionKey special type for represent dimensions key.
@@ -81,4 +197,26 @@ type Dimension struct {
 
 	// Expression contains sql expression for column.
 	Expression string
+
+	// Type contains type of values, filter values and scanned values are converted to it.
+	// Values of dimension without type are used as is.
+	Type DimensionType
+
+	// Enum contains allowed values of dimension with DimensionTypeEnum.
+	Enum []string
+
+	// Format contains layout of values of date and datetime dimensions.
+	Format string
+
+	// Cardinality contains estimated count of distinct values, zero if unknown.
+	Cardinality uint64
+
+	// NullLabel contains label of NULL value of dimension, NULL value is returned as nil.
+	NullLabel string
+
+	// Join contains lookup table of dimension, it is joined only when dimension is grouped, filtered or sorted.
+	Join *Join
+
+	// Dictionary contains human-readable labels of values, labels are added to rows and could be used in filters.
+	Dictionary Dictionary `json:"-"`
 }
@@ -10,6 +10,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.uber.org/zap"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -73,14 +76,14 @@ func requestFromQuery(r *http.Request) (*statistica.ItemsRequest, error) {
 		request.Filters = append(request.Filters, &statistica.ItemsRequestFilter{
 			Key:       "created",
 			Condition: ">=",
-			Values:    []interface{}{rQuery.DateFrom.Format(dateFormat)},
+			Values:    []interface{}{rQuery.DateFrom.Time},
 		})
 	}
 	if !rQuery.DateTo.IsZero() {
 		request.Filters = append(request.Filters, &statistica.ItemsRequestFilter{
 			Key:       "created",
 			Condition: "<=",
-			Values:    []interface{}{rQuery.DateTo.Format(dateFormat)},
+			Values:    []interface{}{rQuery.DateTo.Time},
 		})
 	}
 
@@ -90,6 +93,7 @@ func requestFromQuery(r *http.Request) (*statistica.ItemsRequest, error) {
 func main() {
 	addr := flag.String("addr", ":8080", "")
 	sqlitePath := flag.String("sqlite_path", "./foo.db", "")
+	adminToken := flag.String("admin_token", "", "token of X-Admin-Token header for /explain")
 	flag.Parse()
 
 	db, err := sql.Open("sqlite3", *sqlitePath)
@@ -102,7 +106,12 @@ func main() {
 		log.Fatalf("failed to init DB: %v", err)
 	}
 
-	repository := initRepository(db)
+	exporter, err := otelprometheus.New()
+	if err != nil {
+		log.Fatalf("failed to init prometheus exporter: %v", err)
+	}
+
+	repository := initRepository(db, sdkmetric.NewMeterProvider(sdkmetric.WithReader(exporter)))
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
@@ -129,6 +138,28 @@ func main() {
 		w.Write(data)
 	})
 
+	mux.Handle("/prometheus", promhttp.Handler())
+
+	mux.HandleFunc("/dimensions", func(w http.ResponseWriter, _ *http.Request) {
+		list, err := repository.Dimensions()
+		if err != nil {
+			w.Write([]byte(err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		data, err := json.Marshal(list)
+		if err != nil {
+			w.Write([]byte(err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Write(data)
+	})
+
 	mux.HandleFunc("/total", func(w http.ResponseWriter, r *http.Request) {
 		request, err := requestFromQuery(r)
 		if err != nil {
@@ -182,6 +213,58 @@ func main() {
 		w.Write(body)
 	})
 
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		request, err := requestFromQuery(r)
+		if err != nil {
+			w.Write([]byte(err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		page, err := repository.Page(request)
+		if err != nil {
+			w.Write([]byte(err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		body, err := json.Marshal(page)
+		if err != nil {
+			w.Write([]byte(err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Write(body)
+	})
+
+	mux.HandleFunc("/export", func(w http.ResponseWriter, r *http.Request) {
+		request, err := requestFromQuery(r)
+		if err != nil {
+			w.Write([]byte(err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+
+		// rows are written while query is executed, so export of all groups is not kept in memory.
+		// Rows are limited like rows of /grouped, so export is capped by DefaultLimit and MaxLimit
+		// if repository is created with guardrails.
+		encoder := json.NewEncoder(w)
+
+		err = repository.GroupedEach(r.Context(), request, func(row *statistica.ItemRow) error {
+			return encoder.Encode(row)
+		})
+		if err != nil {
+			w.Write([]byte(err.Error()))
+		}
+	})
+
 	mux.HandleFunc("/values", func(w http.ResponseWriter, r *http.Request) {
 		request, err := requestFromQuery(r)
 		if err != nil {
@@ -210,43 +293,115 @@ func main() {
 		w.Write(body)
 	})
 
+	mux.HandleFunc("/explain", func(w http.ResponseWriter, r *http.Request) {
+		if *adminToken == "" || r.Header.Get("X-Admin-Token") != *adminToken {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		request, err := requestFromQuery(r)
+		if err != nil {
+			w.Write([]byte(err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		method := statistica.QueryMethod(r.URL.Query().Get("method"))
+
+		var plan *statistica.QueryPlan
+		if r.URL.Query().Get("plan") != "" {
+			plan, err = repository.ExplainPlanContext(r.Context(), method, request)
+		} else {
+			plan, err = repository.Explain(method, request)
+		}
+
+		if err != nil {
+			w.Write([]byte(err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		body, err := json.Marshal(plan)
+		if err != nil {
+			w.Write([]byte(err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Write(body)
+	})
+
 	log.Printf("start http server: %s\n", *addr)
 	if err = http.ListenAndServe(*addr, mux); err != nil {
 		log.Fatalf("failed to up http server: %v", err)
 	}
 }
 
-func initRepository(db *sql.DB) *statistica.SQLRepository {
+func initRepository(db *sql.DB, meterProvider *sdkmetric.MeterProvider) *statistica.SQLRepository {
 	return statistica.NewSQLRepository(db, "events",
 		[]*statistica.Dimension{
 			{
 				Name:       "ip",
 				Expression: "ip",
+				Type:       statistica.DimensionTypeIP,
 			},
 			{
 				Name:       "event_type",
 				Expression: "etype",
+				Type:       statistica.DimensionTypeInt,
+				Dictionary: statistica.StaticDictionary{
+					"100": "impression",
+					"101": "click",
+					"102": "conversion",
+				},
 			},
 			{
 				Name:       "created",
-				Expression: "created",
+				Expression: "date(created)",
+				Type:       statistica.DimensionTypeDate,
 			},
 		},
 		[]*statistica.Metric{
 			{
 				Name:       "total",
 				Expression: "count(*)",
+				Unit:       statistica.MetricUnitCount,
+				Direction:  statistica.MetricDirectionHigher,
 			},
 			{
 				Name:       "cost",
 				Expression: "sum(price)",
+				Unit:       statistica.MetricUnitCurrency,
+				Currency:   "USD",
+				Scale:      0.01,
+				Precision:  statistica.MetricPrecision(2),
 			},
 			{
 				Name:       "cpm",
 				Expression: "sum(price)/count(*)",
+				Unit:       statistica.MetricUnitCurrency,
+				Currency:   "USD",
+				Scale:      0.01,
+				Precision:  statistica.MetricPrecision(2),
+				Direction:  statistica.MetricDirectionLower,
+			},
+			{
+				Name:      "median_price",
+				Kind:      statistica.MetricKindMedian,
+				Column:    "price",
+				Unit:      statistica.MetricUnitCurrency,
+				Currency:  "USD",
+				Scale:     0.01,
+				Precision: statistica.MetricPrecision(2),
 			},
 		},
 		statistica.LoggerSQLRepositoryOption(zap.NewExample()),
+		statistica.DialectSQLRepositoryOption(statistica.DialectSQLite),
+		statistica.MeterProviderSQLRepositoryOption(meterProvider),
 	)
 }
 
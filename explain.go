@@ -0,0 +1,157 @@
+package statistica
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnknownMethod returns when query method is not supported.
+	ErrUnknownMethod = errors.New("unknown method")
+	// ErrNotExplainable returns when request is executed by several queries, e.g. with Top, Totals or fallback metrics.
+	ErrNotExplainable = errors.New("request could not be explained by one query")
+)
+
+// QueryMethod special type for represent read method of repository.
+type QueryMethod string
+
+const (
+	// MethodTotal method Total of ReadRepository.
+	MethodTotal QueryMethod = "Total"
+	// MethodValues method Values of ReadRepository.
+	MethodValues QueryMethod = "Values"
+	// MethodGrouped method Grouped of ReadRepository.
+	MethodGrouped QueryMethod = "Grouped"
+	// MethodPage method Page of ReadRepository.
+	MethodPage QueryMethod = "Page"
+)
+
+// QueryPlan this struct represents query which repository runs for request.
+type QueryPlan struct {
+	// Method contains read method of repository.
+	Method QueryMethod `json:"method"`
+
+	// Query contains generated SQL.
+	Query string `json:"query"`
+
+	// Params contains bound parameters of Query.
+	Params []interface{} `json:"params"`
+
+	// Plan contains rows of EXPLAIN output of dialect, empty if query was not explained.
+	Plan []string `json:"plan,omitempty"`
+}
+
+// Explain returns SQL and parameters which method runs for request, without query execution.
+// Page is explained by query of its rows, requests which are executed by several queries are not explained.
+func (r *SQLRepository) Explain(method QueryMethod, req *ItemsRequest) (*QueryPlan, error) {
+	plan := method
+
+	build, ok := map[QueryMethod]func(*SQLRepository, *ItemsRequest) (string, []interface{}){
+		MethodTotal:   (*SQLRepository).buildTotal,
+		MethodValues:  (*SQLRepository).buildValues,
+		MethodGrouped: (*SQLRepository).buildGrouped,
+		MethodPage:    (*SQLRepository).buildGrouped,
+	}[method]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMethod, method)
+	}
+
+	if method == MethodPage {
+		// rows of page are selected with count of groups if dialect supports window functions.
+		if r.windowPageTotal(req) {
+			build = (*SQLRepository).buildPage
+		}
+
+		method = MethodGrouped
+	}
+
+	if method == MethodGrouped {
+		if req.Top != nil || req.Totals {
+			return nil, fmt.Errorf("%w: top and totals", ErrNotExplainable)
+		}
+
+		if err := r.checkWindows(req); err != nil {
+			return nil, err
+		}
+
+		if err := r.checkOrder(req); err != nil {
+			return nil, err
+		}
+
+		seek, err := r.seekRequest(req)
+		if err != nil {
+			return nil, err
+		}
+
+		req = seek
+	}
+
+	req, err := r.prepareRequest(req, method != MethodTotal)
+	if err != nil {
+		return nil, err
+	}
+
+	routed := r.route(method, req)
+	if method == MethodGrouped && len(routed.fallbackMetrics(req)) > 0 {
+		return nil, fmt.Errorf("%w: fallback metrics", ErrNotExplainable)
+	}
+
+	query, params := build(routed, req)
+
+	return &QueryPlan{
+		Method: plan,
+		Query:  query,
+		Params: params,
+	}, nil
+}
+
+// ExplainPlan returns QueryPlan with output of EXPLAIN statement of dialect.
+func (r *SQLRepository) ExplainPlan(method QueryMethod, req *ItemsRequest) (*QueryPlan, error) {
+	return r.ExplainPlanContext(context.Background(), method, req)
+}
+
+// ExplainPlanContext returns QueryPlan with output of EXPLAIN statement of dialect.
+func (r *SQLRepository) ExplainPlanContext(ctx context.Context, method QueryMethod, req *ItemsRequest) (*QueryPlan, error) {
+	plan, err := r.Explain(method, req)
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.dialect.explainPrefix() + plan.Query
+
+	rows, err := r.conn.QueryContext(ctx, query, plan.Params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exec query: %w, query: %s, params: %v", err, query, plan.Params)
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Plan = make([]string, 0)
+
+	for rows.Next() {
+		dest := makeDestFromTypes(types)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		columns := make([]string, len(dest))
+		for i := range dest {
+			columns[i] = fmt.Sprintf("%v", unwrapPointerInterface(dest[i]))
+		}
+
+		plan.Plan = append(plan.Plan, strings.Join(columns, "\t"))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
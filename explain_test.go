@@ -0,0 +1,141 @@
+package statistica
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLRepository_Explain(t *testing.T) {
+	t.Parallel()
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := testRepository(t, db)
+
+	tt := []struct {
+		name     string
+		method   QueryMethod
+		expected *QueryPlan
+	}{
+		{
+			name:   "total",
+			method: MethodTotal,
+			expected: &QueryPlan{
+				Method: MethodTotal,
+				Query: "SELECT count(*) AS total FROM (SELECT 1 FROM test_table  WHERE geo_id IN (?,?,?) " +
+					"GROUP BY  user_id,geo_id) AS grouped",
+				Params: []interface{}{1, 2, 4},
+			},
+		},
+		{
+			name:   "grouped",
+			method: MethodGrouped,
+			expected: &QueryPlan{
+				Method: MethodGrouped,
+				Query: "SELECT user_id,geo_id, count(*) AS total " +
+					"FROM test_table  WHERE geo_id IN (?,?,?) GROUP BY  user_id,geo_id " +
+					"ORDER BY user_id desc LIMIT 1000, 100",
+				Params: []interface{}{1, 2, 4},
+			},
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			plan, err := r.Explain(tc.method, testQuery)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, plan)
+		})
+	}
+
+	_, err = r.Explain("Unknown", testQuery)
+	require.True(t, errors.Is(err, ErrUnknownMethod))
+}
+
+func TestSQLRepository_ExplainNotExplainable(t *testing.T) {
+	t.Parallel()
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}},
+		[]*Metric{
+			{Name: "total", Expression: "count(*)"},
+			{Name: "median", Kind: MetricKindMedian, Column: "price"},
+		},
+	)
+
+	tt := []struct {
+		name string
+		req  *ItemsRequest
+	}{
+		{
+			name: "top",
+			req:  &ItemsRequest{Groups: []string{"geo_id"}, Top: &TopRequest{N: 5, Metric: "total"}},
+		},
+		{
+			name: "totals",
+			req:  &ItemsRequest{Groups: []string{"geo_id"}, Totals: true},
+		},
+		{
+			name: "fallback metrics",
+			req:  &ItemsRequest{Groups: []string{"geo_id"}, Metrics: []string{"median"}},
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, method := range []QueryMethod{MethodGrouped, MethodPage} {
+				_, err := r.Explain(method, tc.req)
+				require.True(t, errors.Is(err, ErrNotExplainable))
+			}
+		})
+	}
+
+	_, err = r.Explain(MethodGrouped, &ItemsRequest{Groups: []string{"geo_id"}, Metrics: []string{"total"}})
+	require.NoError(t, err)
+}
+
+func TestSQLRepository_ExplainPlan(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "user_id", Expression: "user_id"}},
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+		DialectSQLRepositoryOption(DialectSQLite),
+	)
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"EXPLAIN QUERY PLAN SELECT user_id, count(*) AS total FROM test_table  GROUP BY  user_id") + "$",
+		).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent", "notused", "detail"}).
+			AddRow(int64(3), int64(0), int64(0), "SCAN test_table").
+			AddRow(int64(5), int64(0), int64(0), "USE TEMP B-TREE FOR GROUP BY"))
+
+	plan, err := r.ExplainPlan(MethodGrouped, &ItemsRequest{Groups: []string{"user_id"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"3\t0\t0\tSCAN test_table",
+		"5\t0\t0\tUSE TEMP B-TREE FOR GROUP BY",
+	}, plan.Plan)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,86 @@
+package statistica
+
+import "context"
+
+// ValueCountKey sort key of Values which sorts values by count of rows.
+const ValueCountKey = "count"
+
+// FacetRequest this struct represents request of values of dimension in Facets.
+type FacetRequest struct {
+	// Dimension contains key of dimension.
+	Dimension string
+
+	// Limit contains max count of values, all values are returned if limit is 0.
+	Limit int
+
+	// Prefix contains prefix of values, values are not filtered by prefix if it is empty.
+	Prefix string
+}
+
+// Facet this struct represents values of dimension with counts of rows.
+type Facet struct {
+	// Dimension contains key of dimension.
+	Dimension string `json:"dimension"`
+
+	// Values contains values of dimension ordered by count in descending order.
+	Values []*ValueResponse `json:"values"`
+}
+
+// Facets returns values with counts of each requested dimension, queries are executed concurrently.
+// Values of dimension are filtered by all filters of request except filters of this dimension,
+// so selected values of facet do not hide other values of it.
+func Facets(ctx context.Context, repository ReadRepository, req *ItemsRequest, facets ...*FacetRequest) ([]*Facet, error) {
+	result := make([]*Facet, len(facets))
+	tasks := make([]func(ctx context.Context) error, 0, len(facets))
+
+	for i := range facets {
+		i := i
+
+		tasks = append(tasks, func(ctx context.Context) error {
+			values, err := repository.ValuesContext(ctx, facetRequest(req, facets[i]))
+			if err != nil {
+				return err
+			}
+
+			result[i] = &Facet{Dimension: facets[i].Dimension, Values: values}
+
+			return nil
+		})
+	}
+
+	if err := concurrently(ctx, tasks...); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// facetRequest returns request of Values of facet without filters of dimension of facet.
+func facetRequest(req *ItemsRequest, facet *FacetRequest) *ItemsRequest {
+	filters := make([]*ItemsRequestFilter, 0, len(req.Filters)+1)
+
+	for _, filter := range req.Filters {
+		if filter.Key != facet.Dimension {
+			filters = append(filters, filter)
+		}
+	}
+
+	if facet.Prefix != "" {
+		filters = append(filters, &ItemsRequestFilter{
+			Key:       facet.Dimension,
+			Condition: CondPrefix,
+			Values:    []interface{}{facet.Prefix},
+		})
+	}
+
+	return &ItemsRequest{
+		Cube:    req.Cube,
+		Limit:   facet.Limit,
+		Groups:  []string{facet.Dimension},
+		Filters: filters,
+		SortBy: []*ItemsRequestOrder{
+			{Key: ValueCountKey, Direction: "DESC"},
+			{Key: facet.Dimension, Direction: "ASC"},
+		},
+	}
+}
@@ -0,0 +1,133 @@
+package statistica
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func testFacetsRepository(t *testing.T, dialect Dialect) (*SQLRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}, {Name: "domain", Expression: "domain"}},
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+		DialectSQLRepositoryOption(dialect),
+	), mock
+}
+
+func TestFacets(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testFacetsRepository(t, DialectSQLite)
+	mock.MatchExpectationsInOrder(false)
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT geo_id, count(*) AS total FROM test_table WHERE domain IN (?) "+
+					"GROUP BY geo_id ORDER BY count(*) DESC,geo_id ASC LIMIT 2") + "$",
+		).
+		WithArgs("example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total"}).
+			AddRow(int64(1), int64(10)).
+			AddRow(int64(2), int64(5)))
+
+	mock.
+		ExpectQuery(
+			"^"+regexp.QuoteMeta(
+				"SELECT domain, count(*) AS total FROM test_table WHERE geo_id IN (?) AND domain LIKE ? ESCAPE '\\' "+
+					"GROUP BY domain ORDER BY count(*) DESC,domain ASC")+"$",
+		).
+		WithArgs(int64(1), `ex\_%`).
+		WillReturnRows(sqlmock.NewRows([]string{"domain", "total"}).AddRow("ex_ample.com", int64(3)))
+
+	facets, err := Facets(context.Background(), r,
+		&ItemsRequest{
+			Filters: []*ItemsRequestFilter{
+				{Key: "geo_id", Condition: CondEq, Values: []interface{}{int64(1)}},
+				{Key: "domain", Condition: CondEq, Values: []interface{}{"example.com"}},
+			},
+		},
+		&FacetRequest{Dimension: "geo_id", Limit: 2},
+		&FacetRequest{Dimension: "domain", Prefix: "ex_"},
+	)
+	require.NoError(t, err)
+	require.Len(t, facets, 2)
+
+	require.Equal(t, "geo_id", facets[0].Dimension)
+	require.Len(t, facets[0].Values, 2)
+	require.Equal(t, ValueNumber(10), facets[0].Values[0].Count)
+
+	require.Equal(t, "domain", facets[1].Dimension)
+	require.Equal(t, []interface{}{"ex_ample.com"}, facets[1].Values[0].Key)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFacets_Error(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testFacetsRepository(t, DialectSQLite)
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectQuery("SELECT geo_id").WillReturnError(errors.New("connection lost"))
+	mock.ExpectQuery("SELECT domain").WillReturnRows(sqlmock.NewRows([]string{"domain", "total"}))
+
+	_, err := Facets(context.Background(), r, &ItemsRequest{},
+		&FacetRequest{Dimension: "geo_id"},
+		&FacetRequest{Dimension: "domain"},
+	)
+	require.ErrorContains(t, err, "connection lost")
+}
+
+func TestSQLRepository_Like(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		dialect Dialect
+		filter  *ItemsRequestFilter
+		where   string
+		params  []interface{}
+	}{
+		{
+			dialect: DialectSQLite,
+			filter:  &ItemsRequestFilter{Key: "domain", Condition: CondLike, Values: []interface{}{"50%"}},
+			where:   `domain LIKE ? ESCAPE '\'`,
+			params:  []interface{}{`%50\%%`},
+		},
+		{
+			dialect: DialectPostgres,
+			filter:  &ItemsRequestFilter{Key: "domain", Condition: CondPrefix, Values: []interface{}{"a", "b"}},
+			where:   `(domain LIKE $1 ESCAPE '\' OR domain LIKE $2 ESCAPE '\')`,
+			params:  []interface{}{"a%", "b%"},
+		},
+		{
+			dialect: DialectClickHouse,
+			filter:  &ItemsRequestFilter{Key: "domain", Condition: CondPrefix, Values: []interface{}{`c:\`}},
+			where:   `domain LIKE ?`,
+			params:  []interface{}{`c:\\%`},
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(string(tc.dialect), func(t *testing.T) {
+			t.Parallel()
+
+			r, _ := testFacetsRepository(t, tc.dialect)
+
+			plan, err := r.Explain(MethodTotal, &ItemsRequest{Filters: []*ItemsRequestFilter{tc.filter}})
+			require.NoError(t, err)
+			require.Equal(t, "SELECT count(*) AS total FROM test_table WHERE "+tc.where, plan.Query)
+			require.Equal(t, tc.params, plan.Params)
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package statistica
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrTooManyGroups returns when request contains more groups than allowed.
+	ErrTooManyGroups = errors.New("too many groups")
+	// ErrLimitExceeded returns when request limit is absent or greater than allowed.
+	ErrLimitExceeded = errors.New("limit exceeded")
+	// ErrDateRangeRequired returns when request has no bounded range on date dimension.
+	ErrDateRangeRequired = errors.New("date range required")
+	// ErrDateRangeExceeded returns when request range on date dimension is greater than allowed.
+	ErrDateRangeExceeded = errors.New("date range exceeded")
+	// ErrFilterRequired returns when request has no required filter.
+	ErrFilterRequired = errors.New("filter required")
+	// ErrCardinalityExceeded returns when estimated number of groups is greater than allowed.
+	ErrCardinalityExceeded = errors.New("cardinality exceeded")
+)
+
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	time.RFC3339Nano,
+}
+
+// GuardrailError this struct describes request rejected by Guardrails.
+type GuardrailError struct {
+	// Err contains one of guardrail errors like ErrTooManyGroups.
+	Err error
+
+	// Detail contains human-readable description of violation.
+	Detail string
+}
+
+func (e *GuardrailError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Err, e.Detail)
+}
+
+func (e *GuardrailError) Unwrap() error {
+	return e.Err
+}
+
+// Guardrails this struct describes limits checked before query is built.
+// Zero value of each field disables the check.
+type Guardrails struct {
+	// MaxGroups contains max count of groups in request.
+	MaxGroups int
+
+	// DefaultLimit contains limit applied to request without limit.
+	DefaultLimit int
+
+	// MaxLimit contains max limit of request, request without limit is rejected.
+	MaxLimit int
+
+	// DateDimension contains dimension used for check MaxDateRange.
+	DateDimension DimensionKey
+
+	// MaxDateRange contains max time span of filters on DateDimension.
+	MaxDateRange time.Duration
+
+	// RequiredFilters contains dimensions which must be filtered in every request.
+	RequiredFilters []DimensionKey
+
+	// MaxCardinality contains max product of Dimension.Cardinality of request groups.
+	MaxCardinality uint64
+}
+
+// GuardrailsSQLRepositoryOption sets guardrails checked by every query.
+func GuardrailsSQLRepositoryOption(guardrails *Guardrails) SQLRepositoryOption {
+	return func(repository *SQLRepository) {
+		repository.guardrails = guardrails
+	}
+}
+
+// applyGuardrails returns copy of request with applied defaults or error if request violates guardrails.
+func (r *SQLRepository) applyGuardrails(req *ItemsRequest, limited bool) (*ItemsRequest, error) {
+	g := r.guardrails
+	if g == nil {
+		return req, nil
+	}
+
+	if g.MaxGroups > 0 && len(req.Groups) > g.MaxGroups {
+		return nil, &GuardrailError{
+			Err:    ErrTooManyGroups,
+			Detail: fmt.Sprintf("%d groups requested, max %d", len(req.Groups), g.MaxGroups),
+		}
+	}
+
+	if err := r.checkCardinality(req); err != nil {
+		return nil, err
+	}
+
+	for _, key := range g.RequiredFilters {
+		if !hasFilter(req, key) {
+			return nil, &GuardrailError{Err: ErrFilterRequired, Detail: string(key)}
+		}
+	}
+
+	if err := g.checkDateRange(req); err != nil {
+		return nil, err
+	}
+
+	applied := *req
+
+	if limited {
+		if applied.Limit <= 0 {
+			applied.Limit = g.DefaultLimit
+		}
+
+		if g.MaxLimit > 0 && (applied.Limit <= 0 || applied.Limit > g.MaxLimit) {
+			return nil, &GuardrailError{
+				Err:    ErrLimitExceeded,
+				Detail: fmt.Sprintf("limit %d requested, max %d", applied.Limit, g.MaxLimit),
+			}
+		}
+	}
+
+	return &applied, nil
+}
+
+func (r *SQLRepository) checkCardinality(req *ItemsRequest) error {
+	if r.guardrails.MaxCardinality == 0 {
+		return nil
+	}
+
+	cardinality := uint64(1)
+
+	for _, key := range req.Groups {
+		dim, exists := r.getDimension(DimensionKey(key))
+		if !exists || dim.Cardinality == 0 {
+			continue
+		}
+
+		if cardinality > r.guardrails.MaxCardinality/dim.Cardinality {
+			return &GuardrailError{
+				Err:    ErrCardinalityExceeded,
+				Detail: fmt.Sprintf("groups %v, max %d", req.Groups, r.guardrails.MaxCardinality),
+			}
+		}
+
+		cardinality *= dim.Cardinality
+	}
+
+	return nil
+}
+
+func (g *Guardrails) checkDateRange(req *ItemsRequest) error {
+	if g.DateDimension == "" || g.MaxDateRange <= 0 {
+		return nil
+	}
+
+	var from, to, eqFrom, eqTo time.Time
+
+	for _, filter := range req.Filters {
+		if DimensionKey(filter.Key) != g.DateDimension {
+			continue
+		}
+
+		for _, value := range filter.Values {
+			t, ok := parseDate(value)
+			if !ok {
+				return &GuardrailError{
+					Err:    ErrDateRangeRequired,
+					Detail: fmt.Sprintf("failed to parse date %v", value),
+				}
+			}
+
+			switch filter.Condition {
+			case CondGreater, CondGreaterOrEq:
+				from = maxTime(from, t)
+			case CondLess, CondLessOrEq:
+				to = minTime(to, t)
+			case CondEq, CondEq2, "":
+				eqFrom = minTime(eqFrom, t)
+				eqTo = maxTime(eqTo, t)
+			}
+		}
+	}
+
+	if !eqFrom.IsZero() {
+		from = maxTime(from, eqFrom)
+		to = minTime(to, eqTo)
+	}
+
+	if from.IsZero() || to.IsZero() {
+		return &GuardrailError{Err: ErrDateRangeRequired, Detail: string(g.DateDimension)}
+	}
+
+	if to.Sub(from) > g.MaxDateRange {
+		return &GuardrailError{
+			Err:    ErrDateRangeExceeded,
+			Detail: fmt.Sprintf("%s requested, max %s", to.Sub(from), g.MaxDateRange),
+		}
+	}
+
+	return nil
+}
+
+func hasFilter(req *ItemsRequest, key DimensionKey) bool {
+	for _, filter := range req.Filters {
+		if DimensionKey(filter.Key) == key && len(filter.Values) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseDate(value interface{}) (time.Time, bool) {
+	switch t := value.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		return *t, true
+	case string:
+		for i := range dateLayouts {
+			if d, err := time.Parse(dateLayouts[i], t); err == nil {
+				return d, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.IsZero() || b.After(a) {
+		return b
+	}
+
+	return a
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.IsZero() || b.Before(a) {
+		return b
+	}
+
+	return a
+}
@@ -0,0 +1,146 @@
+package statistica
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func testGuardrailsRepository(t *testing.T) (*SQLRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{
+			{Name: "user_id", Expression: "user_id", Cardinality: 100000},
+			{Name: "geo_id", Expression: "geo_id", Cardinality: 200},
+			{Name: "date", Expression: "date"},
+		},
+		[]*Metric{
+			{Name: "total", Expression: "count(*)"},
+		},
+		GuardrailsSQLRepositoryOption(&Guardrails{
+			MaxGroups:       2,
+			DefaultLimit:    10,
+			MaxLimit:        100,
+			DateDimension:   "date",
+			MaxDateRange:    31 * 24 * time.Hour,
+			RequiredFilters: []DimensionKey{"date"},
+			MaxCardinality:  1000000,
+		}),
+	)
+
+	return r, mock
+}
+
+func testGuardrailsDateFilters(from, to string) []*ItemsRequestFilter {
+	return []*ItemsRequestFilter{
+		{Key: "date", Condition: CondGreaterOrEq, Values: []interface{}{from}},
+		{Key: "date", Condition: CondLessOrEq, Values: []interface{}{to}},
+	}
+}
+
+func TestGuardrails_Reject(t *testing.T) {
+	t.Parallel()
+
+	r, _ := testGuardrailsRepository(t)
+
+	tt := []struct {
+		name     string
+		request  *ItemsRequest
+		expected error
+	}{
+		{
+			name: "too many groups",
+			request: &ItemsRequest{
+				Groups:  []string{"user_id", "geo_id", "date"},
+				Filters: testGuardrailsDateFilters("2022-10-01", "2022-10-02"),
+			},
+			expected: ErrTooManyGroups,
+		},
+		{
+			name: "cardinality",
+			request: &ItemsRequest{
+				Groups:  []string{"user_id", "geo_id"},
+				Filters: testGuardrailsDateFilters("2022-10-01", "2022-10-02"),
+			},
+			expected: ErrCardinalityExceeded,
+		},
+		{
+			name:     "required filter",
+			request:  &ItemsRequest{Groups: []string{"geo_id"}},
+			expected: ErrFilterRequired,
+		},
+		{
+			name: "unbounded date range",
+			request: &ItemsRequest{
+				Filters: []*ItemsRequestFilter{
+					{Key: "date", Condition: CondGreaterOrEq, Values: []interface{}{"2022-10-01"}},
+				},
+			},
+			expected: ErrDateRangeRequired,
+		},
+		{
+			name: "date range",
+			request: &ItemsRequest{
+				Filters: testGuardrailsDateFilters("2022-01-01", "2022-10-02"),
+			},
+			expected: ErrDateRangeExceeded,
+		},
+		{
+			name: "limit",
+			request: &ItemsRequest{
+				Limit:   1000,
+				Filters: testGuardrailsDateFilters("2022-10-01", "2022-10-02"),
+			},
+			expected: ErrLimitExceeded,
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := r.Grouped(tc.request)
+			require.True(t, errors.Is(err, tc.expected), err)
+
+			var guardErr *GuardrailError
+			require.True(t, errors.As(err, &guardErr))
+		})
+	}
+}
+
+func TestGuardrails_DefaultLimit(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testGuardrailsRepository(t)
+
+	mock.
+		ExpectQuery(
+			"^"+regexp.QuoteMeta(
+				"SELECT geo_id, count(*) AS total "+
+					"FROM test_table WHERE date IN (?,?) GROUP BY  geo_id LIMIT 10")+"$",
+		).
+		WithArgs("2022-10-01", "2022-10-03").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total"}))
+
+	req := &ItemsRequest{
+		Groups: []string{"geo_id"},
+		Filters: []*ItemsRequestFilter{
+			{Key: "date", Condition: CondEq, Values: []interface{}{"2022-10-01", "2022-10-03"}},
+		},
+	}
+
+	_, err := r.Grouped(req)
+	require.NoError(t, err)
+	require.Equal(t, 0, req.Limit)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,145 @@
+package statistica
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrHierarchyNotFound returns when repository has no hierarchy with name.
+	ErrHierarchyNotFound = errors.New("hierarchy not found")
+	// ErrHierarchyLevel returns when drill down or roll up goes out of levels of hierarchy.
+	ErrHierarchyLevel = errors.New("invalid hierarchy level")
+)
+
+// Hierarchy this struct describe ordered levels of dimensions from parent to child,
+// like country > region > city.
+type Hierarchy struct {
+	// Name contains name for represent hierarchy.
+	Name string
+
+	// Description contains description of hierarchy.
+	Description string
+
+	// Levels contains dimensions of hierarchy from top level to bottom level.
+	Levels []DimensionKey
+}
+
+// HierarchiesSQLRepositoryOption sets hierarchies of dimensions.
+func HierarchiesSQLRepositoryOption(hierarchies ...*Hierarchy) SQLRepositoryOption {
+	return func(repository *SQLRepository) {
+		repository.hierarchies = append(repository.hierarchies, hierarchies...)
+	}
+}
+
+// Hierarchies returns hierarchies of dimensions.
+func (r *SQLRepository) Hierarchies() ([]*Hierarchy, error) {
+	return r.hierarchies, nil
+}
+
+// FindHierarchy returns hierarchy of repository by name.
+func FindHierarchy(repository ReadRepository, name string) (*Hierarchy, error) {
+	hierarchies, err := repository.Hierarchies()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range hierarchies {
+		if hierarchies[i].Name == name {
+			return hierarchies[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrHierarchyNotFound, name)
+}
+
+// Level returns index of deepest level of hierarchy in groups of request, -1 if request is not grouped by hierarchy.
+func (h *Hierarchy) Level(req *ItemsRequest) int {
+	level := -1
+
+	for _, group := range req.Groups {
+		if i := h.levelIndex(DimensionKey(group)); i > level {
+			level = i
+		}
+	}
+
+	return level
+}
+
+// DrillDown returns copy of request filtered by values of path from top level
+// and grouped by next level of hierarchy. Empty path returns request grouped by top level.
+func (h *Hierarchy) DrillDown(req *ItemsRequest, path ...interface{}) (*ItemsRequest, error) {
+	if len(path) >= len(h.Levels) {
+		return nil, fmt.Errorf("%w: %s has %d levels, path has %d values",
+			ErrHierarchyLevel, h.Name, len(h.Levels), len(path))
+	}
+
+	drilled := h.withLevel(req, len(path), 0)
+
+	for i := range path {
+		drilled.Filters = append(drilled.Filters, &ItemsRequestFilter{
+			Key:       string(h.Levels[i]),
+			Values:    []interface{}{path[i]},
+			Condition: CondEq,
+		})
+	}
+
+	return drilled, nil
+}
+
+// RollUp returns copy of request grouped by parent level of deepest level of hierarchy in groups,
+// filter of parent level is removed. Roll up of top level returns request without groups of hierarchy.
+func (h *Hierarchy) RollUp(req *ItemsRequest) (*ItemsRequest, error) {
+	level := h.Level(req)
+	if level < 0 {
+		return nil, fmt.Errorf("%w: request is not grouped by %s", ErrHierarchyLevel, h.Name)
+	}
+
+	return h.withLevel(req, level-1, level-1), nil
+}
+
+// withLevel returns copy of request grouped by level of hierarchy, groups and sorting of other levels
+// and filters of levels from keepFilters are removed. Negative level removes all groups of hierarchy.
+func (h *Hierarchy) withLevel(req *ItemsRequest, level, keepFilters int) *ItemsRequest {
+	result := *req
+	result.Groups = make([]string, 0, len(req.Groups)+1)
+	result.Filters = make([]*ItemsRequestFilter, 0, len(req.Filters)+len(h.Levels))
+
+	for _, group := range req.Groups {
+		if h.levelIndex(DimensionKey(group)) < 0 {
+			result.Groups = append(result.Groups, group)
+		}
+	}
+
+	if level >= 0 {
+		result.Groups = append(result.Groups, string(h.Levels[level]))
+	}
+
+	for _, filter := range req.Filters {
+		i := h.levelIndex(DimensionKey(filter.Key))
+		if i < 0 || i < keepFilters {
+			result.Filters = append(result.Filters, filter)
+		}
+	}
+
+	// order by removed levels is not allowed in grouped query.
+	result.SortBy = make([]*ItemsRequestOrder, 0, len(req.SortBy))
+
+	for _, order := range req.SortBy {
+		if i := h.levelIndex(DimensionKey(order.Key)); i < 0 || i == level {
+			result.SortBy = append(result.SortBy, order)
+		}
+	}
+
+	return &result
+}
+
+func (h *Hierarchy) levelIndex(key DimensionKey) int {
+	for i := range h.Levels {
+		if h.Levels[i] == key {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,125 @@
+package statistica
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func testGeoHierarchy() *Hierarchy {
+	return &Hierarchy{
+		Name:   "geo",
+		Levels: []DimensionKey{"country", "region", "city"},
+	}
+}
+
+func TestHierarchy_DrillDown(t *testing.T) {
+	t.Parallel()
+
+	h := testGeoHierarchy()
+	source := &ItemsRequest{
+		Groups:  []string{"country", "user_id"},
+		SortBy:  []*ItemsRequestOrder{{Key: "country"}, {Key: "user_id"}},
+		Filters: []*ItemsRequestFilter{{Key: "user_id", Values: []interface{}{1}}},
+	}
+
+	req, err := h.DrillDown(source, "DE")
+	require.NoError(t, err)
+	require.Equal(t, []string{"user_id", "region"}, req.Groups)
+	require.Equal(t, []*ItemsRequestOrder{{Key: "user_id"}}, req.SortBy)
+	require.Equal(t, []*ItemsRequestFilter{
+		{Key: "user_id", Values: []interface{}{1}},
+		{Key: "country", Values: []interface{}{"DE"}, Condition: CondEq},
+	}, req.Filters)
+	require.Equal(t, []string{"country", "user_id"}, source.Groups)
+
+	req, err = h.DrillDown(req, "DE", "Bavaria")
+	require.NoError(t, err)
+	require.Equal(t, []string{"user_id", "city"}, req.Groups)
+	require.Len(t, req.Filters, 3)
+	require.Equal(t, 2, h.Level(req))
+
+	_, err = h.DrillDown(req, "DE", "Bavaria", "Munich")
+	require.True(t, errors.Is(err, ErrHierarchyLevel))
+}
+
+func TestHierarchy_RollUp(t *testing.T) {
+	t.Parallel()
+
+	h := testGeoHierarchy()
+
+	req, err := h.DrillDown(&ItemsRequest{
+		Filters: []*ItemsRequestFilter{{Key: "user_id", Values: []interface{}{1}}},
+	}, "DE", "Bavaria")
+	require.NoError(t, err)
+
+	req, err = h.RollUp(req)
+	require.NoError(t, err)
+	require.Equal(t, []string{"region"}, req.Groups)
+	require.Equal(t, []*ItemsRequestFilter{
+		{Key: "user_id", Values: []interface{}{1}},
+		{Key: "country", Values: []interface{}{"DE"}, Condition: CondEq},
+	}, req.Filters)
+
+	req, err = h.RollUp(req)
+	require.NoError(t, err)
+	require.Equal(t, []string{"country"}, req.Groups)
+	require.Equal(t, []*ItemsRequestFilter{{Key: "user_id", Values: []interface{}{1}}}, req.Filters)
+
+	req, err = h.RollUp(req)
+	require.NoError(t, err)
+	require.Empty(t, req.Groups)
+	require.Equal(t, -1, h.Level(req))
+
+	_, err = h.RollUp(req)
+	require.True(t, errors.Is(err, ErrHierarchyLevel))
+}
+
+func TestSQLRepository_Hierarchies(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{
+			{Name: "country", Expression: "country"},
+			{Name: "region", Expression: "region"},
+			{Name: "city", Expression: "city"},
+		},
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+		HierarchiesSQLRepositoryOption(testGeoHierarchy()),
+	)
+
+	h, err := FindHierarchy(r, "geo")
+	require.NoError(t, err)
+
+	_, err = FindHierarchy(r, "time")
+	require.True(t, errors.Is(err, ErrHierarchyNotFound))
+
+	req, err := h.DrillDown(&ItemsRequest{}, "DE")
+	require.NoError(t, err)
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT region, count(*) AS total FROM test_table WHERE country IN (?) GROUP BY region") + "$",
+		).
+		WithArgs("DE").
+		WillReturnRows(sqlmock.NewRows([]string{"region", "total"}).AddRow("Bavaria", int64(3)))
+
+	rows, err := r.Grouped(req)
+	require.NoError(t, err)
+	require.Equal(t, "Bavaria", rows[0].Dimensions["region"])
+
+	access := NewAccessRepository(r, &RoleAccessPolicy{
+		Dimensions: map[DimensionKey][]string{"city": {"internal"}},
+	}, &Principal{ID: "guest"})
+
+	hierarchies, err := access.Hierarchies()
+	require.NoError(t, err)
+	require.Equal(t, []DimensionKey{"country", "region"}, hierarchies[0].Levels)
+}
@@ -0,0 +1,93 @@
+package statistica
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JoinType special type for represent type of sql join.
+type JoinType string
+
+const (
+	// JoinLeft keeps rows of table without rows in joined table, it is default type of join.
+	JoinLeft JoinType = "LEFT"
+	// JoinInner keeps only rows of table with rows in joined table.
+	JoinInner JoinType = "INNER"
+)
+
+// JoinKey this struct describe pair of columns used for join.
+type JoinKey struct {
+	// Column contains sql expression of column of table.
+	Column string
+
+	// Foreign contains sql expression of column of joined table.
+	Foreign string
+}
+
+// Join this struct describe lookup table joined to table of repository.
+type Join struct {
+	// Table contains name of joined table or sql expression like table with alias, e.g. "campaigns AS c".
+	Table string
+
+	// Keys contains columns used for join.
+	Keys []JoinKey
+
+	// Type contains type of join, JoinLeft by default.
+	Type JoinType
+}
+
+// String returns sql clause of join.
+func (j *Join) String() string {
+	joinType := j.Type
+	if joinType == "" {
+		joinType = JoinLeft
+	}
+
+	on := make([]string, 0, len(j.Keys))
+	for i := range j.Keys {
+		on = append(on, fmt.Sprintf("%s = %s", j.Keys[i].Column, j.Keys[i].Foreign))
+	}
+
+	return fmt.Sprintf("%s JOIN %s ON %s", joinType, j.Table, strings.Join(on, " AND "))
+}
+
+// applyFrom appends table and joins of dimensions used in groups, filters, sorting or search of request.
+func (r *SQLRepository) applyFrom(req *ItemsRequest, query *string) {
+	*query += fmt.Sprintf(" FROM %s", r.table)
+
+	used := make(map[DimensionKey]struct{})
+
+	for _, group := range req.Groups {
+		used[DimensionKey(group)] = struct{}{}
+	}
+
+	for _, filter := range req.Filters {
+		used[DimensionKey(filter.Key)] = struct{}{}
+	}
+
+	for _, order := range req.SortBy {
+		used[DimensionKey(order.Key)] = struct{}{}
+	}
+
+	if req.Search != nil && req.Search.Term != "" {
+		for _, key := range req.Search.Dimensions {
+			used[DimensionKey(key)] = struct{}{}
+		}
+	}
+
+	joined := make(map[string]struct{})
+
+	for _, dim := range r.dimensions {
+		if _, ok := used[dim.Name]; !ok || dim.Join == nil {
+			continue
+		}
+
+		clause := dim.Join.String()
+		if _, ok := joined[clause]; ok {
+			continue
+		}
+
+		joined[clause] = struct{}{}
+		*query += " " + clause
+	}
+}
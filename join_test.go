@@ -0,0 +1,177 @@
+package statistica
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoin_String(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t,
+		"LEFT JOIN campaigns AS c ON campaign_id = c.id",
+		(&Join{Table: "campaigns AS c", Keys: []JoinKey{{Column: "campaign_id", Foreign: "c.id"}}}).String(),
+	)
+	require.Equal(t,
+		"INNER JOIN campaigns AS c ON campaign_id = c.id AND geo_id = c.geo_id",
+		(&Join{
+			Table: "campaigns AS c",
+			Keys:  []JoinKey{{Column: "campaign_id", Foreign: "c.id"}, {Column: "geo_id", Foreign: "c.geo_id"}},
+			Type:  JoinInner,
+		}).String(),
+	)
+}
+
+func TestSQLRepository_Join(t *testing.T) {
+	t.Parallel()
+
+	campaigns := &Join{Table: "campaigns AS c", Keys: []JoinKey{{Column: "campaign_id", Foreign: "c.id"}}}
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{
+			{Name: "campaign_id", Expression: "campaign_id"},
+			{Name: "campaign", Expression: "c.name", Join: campaigns},
+			{Name: "advertiser", Expression: "c.advertiser", Join: campaigns},
+		},
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+	)
+
+	tt := []struct {
+		name  string
+		req   *ItemsRequest
+		query string
+	}{
+		{
+			name:  "without join",
+			req:   &ItemsRequest{Groups: []string{"campaign_id"}},
+			query: "SELECT campaign_id, count(*) AS total FROM test_table GROUP BY campaign_id",
+		},
+		{
+			name: "grouped",
+			req:  &ItemsRequest{Groups: []string{"campaign", "advertiser"}},
+			query: "SELECT c.name,c.advertiser, count(*) AS total FROM test_table " +
+				"LEFT JOIN campaigns AS c ON campaign_id = c.id GROUP BY c.name,c.advertiser",
+		},
+		{
+			name: "filtered",
+			req: &ItemsRequest{
+				Groups:  []string{"campaign_id"},
+				Filters: []*ItemsRequestFilter{{Key: "advertiser", Values: []interface{}{"acme"}}},
+			},
+			query: "SELECT campaign_id, count(*) AS total FROM test_table " +
+				"LEFT JOIN campaigns AS c ON campaign_id = c.id WHERE c.advertiser IN (?) GROUP BY campaign_id",
+		},
+		{
+			name: "sorted",
+			req: &ItemsRequest{
+				Groups: []string{"campaign_id"},
+				SortBy: []*ItemsRequestOrder{{Key: "campaign", Direction: "ASC"}},
+			},
+			query: "SELECT campaign_id, count(*) AS total FROM test_table " +
+				"LEFT JOIN campaigns AS c ON campaign_id = c.id GROUP BY campaign_id ORDER BY c.name ASC",
+		},
+		{
+			name: "searched",
+			req: &ItemsRequest{
+				Groups: []string{"campaign_id"},
+				Search: &SearchRequest{Term: "acme", Dimensions: []string{"campaign"}},
+			},
+			query: "SELECT campaign_id, count(*) AS total FROM test_table " +
+				"LEFT JOIN campaigns AS c ON campaign_id = c.id WHERE lower(c.name) LIKE ? ESCAPE '\\' GROUP BY campaign_id",
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		mock.ExpectQuery("^" + regexp.QuoteMeta(tc.query) + "$").
+			WillReturnRows(sqlmock.NewRows([]string{"total"}))
+
+		_, err := r.Grouped(tc.req)
+		require.NoError(t, err, tc.name)
+	}
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepository_JoinSearchSummary(t *testing.T) {
+	t.Parallel()
+
+	campaigns := &Join{Table: "campaigns AS c", Keys: []JoinKey{{Column: "campaign_id", Foreign: "c.id"}}}
+	from := "FROM test_table LEFT JOIN campaigns AS c ON campaign_id = c.id WHERE lower(c.name) LIKE ? ESCAPE '\\'"
+	summary := "SELECT count(*) AS total " + from
+
+	tt := []struct {
+		name    string
+		req     *ItemsRequest
+		queries []string
+	}{
+		{
+			name: "top",
+			req:  &ItemsRequest{Top: &TopRequest{N: 5, Metric: "total"}},
+			queries: []string{
+				"SELECT c.name, count(*) AS total " + from + " GROUP BY c.name ORDER BY total DESC LIMIT 6",
+				summary,
+			},
+		},
+		{
+			name: "totals",
+			req:  &ItemsRequest{Totals: true},
+			queries: []string{
+				"SELECT c.name, count(*) AS total " + from + " GROUP BY c.name",
+				summary,
+			},
+		},
+		{
+			name: "page",
+			req:  &ItemsRequest{Limit: 10},
+			queries: []string{
+				"SELECT c.name, count(*) AS total " + from + " GROUP BY c.name LIMIT 10",
+				summary,
+				"SELECT count(*) AS total FROM (SELECT 1 " + from + " GROUP BY c.name) AS grouped",
+			},
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			mock.MatchExpectationsInOrder(false)
+
+			r := NewSQLRepository(db, testTable,
+				[]*Dimension{{Name: "campaign", Expression: "c.name", Join: campaigns}},
+				[]*Metric{{Name: "total", Expression: "count(*)", Merge: MetricMergeSum}},
+			)
+
+			for _, query := range tc.queries {
+				mock.ExpectQuery("^" + regexp.QuoteMeta(query) + "$").
+					WithArgs("acme%").
+					WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(int64(1)))
+			}
+
+			tc.req.Groups = []string{"campaign"}
+			tc.req.Search = &SearchRequest{Term: "acme"}
+
+			if tc.req.Limit > 0 {
+				_, err = r.Page(tc.req)
+			} else {
+				_, err = r.Grouped(tc.req)
+			}
+
+			require.NoError(t, err)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
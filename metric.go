@@ -0,0 +1,423 @@
+package statistica
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsortableMetric returns when request is sorted by metric which is computed in Go,
+// database could not sort or page rows by such metric.
+var ErrUnsortableMetric = errors.New("metric could not be sorted")
+
+// MetricKind special type for represent kind of metric, metric of kind is rendered by dialect.
+type MetricKind string
+
+const (
+	// MetricKindExpression metric computed by Metric.Expression, it is default kind.
+	MetricKindExpression MetricKind = ""
+	// MetricKindQuantile quantile Metric.Quantile of Metric.Column.
+	MetricKindQuantile MetricKind = "quantile"
+	// MetricKindMedian median of Metric.Column.
+	MetricKindMedian MetricKind = "median"
+	// MetricKindDistinct exact count of distinct values of Metric.Column.
+	MetricKindDistinct MetricKind = "distinct"
+	// MetricKindApproxDistinct approximate count of distinct values of Metric.Column,
+	// exact count is used by dialects without approximate count.
+	MetricKindApproxDistinct MetricKind = "approx_distinct"
+)
+
+// MetricUnit special type for represent unit of metric values.
+type MetricUnit string
+
+const (
+	// MetricUnitNone value without unit.
+	MetricUnitNone MetricUnit = ""
+	// MetricUnitCount count of items.
+	MetricUnitCount MetricUnit = "count"
+	// MetricUnitCurrency amount of money in Metric.Currency.
+	MetricUnitCurrency MetricUnit = "currency"
+	// MetricUnitPercent percentage, ratio needs Metric.Scale 100.
+	MetricUnitPercent MetricUnit = "percent"
+	// MetricUnitSeconds duration in seconds.
+	MetricUnitSeconds MetricUnit = "seconds"
+	// MetricUnitBytes size in bytes.
+	MetricUnitBytes MetricUnit = "bytes"
+)
+
+// MetricDirection special type for represent which change of metric value is better.
+type MetricDirection string
+
+const (
+	// MetricDirectionNone metric without better direction.
+	MetricDirectionNone MetricDirection = ""
+	// MetricDirectionHigher higher value is better, e.g. revenue.
+	MetricDirectionHigher MetricDirection = "higher"
+	// MetricDirectionLower lower value is better, e.g. latency.
+	MetricDirectionLower MetricDirection = "lower"
+)
+
+const medianQuantile = 0.5
+
+// MetricPrecision returns precision of displayed value with count of digits after decimal point for Metric.Precision.
+func MetricPrecision(digits int) *int {
+	return &digits
+}
+
+// Format returns value scaled and rounded to precision with unit of metric, e.g. "12.50 USD" or "4.2%".
+// Value which represents NULL is formatted as empty string.
+func (m *Metric) Format(value ValueNumber) string {
+	if !value.Valid() {
+		return ""
+	}
+
+	scale := m.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	precision := -1
+	if m.Precision != nil {
+		precision = *m.Precision
+	}
+
+	formatted := strconv.FormatFloat(float64(value)*scale, 'f', precision, 64)
+
+	switch m.Unit {
+	case MetricUnitCurrency:
+		if m.Currency != "" {
+			return formatted + " " + m.Currency
+		}
+	case MetricUnitPercent:
+		return formatted + "%"
+	case MetricUnitSeconds:
+		return formatted + "s"
+	case MetricUnitBytes:
+		return formatted + " B"
+	}
+
+	return formatted
+}
+
+// FormatMetrics returns formatted values of metrics by name for exports,
+// values of unknown metrics are formatted with smallest count of digits.
+func FormatMetrics(metrics []*Metric, values map[string]ValueNumber) map[string]string {
+	byName := make(map[string]*Metric, len(metrics))
+	for i := range metrics {
+		byName[metrics[i].Name] = metrics[i]
+	}
+
+	formatted := make(map[string]string, len(values))
+
+	for name, value := range values {
+		m, ok := byName[name]
+		if !ok {
+			m = &Metric{Name: name}
+		}
+
+		formatted[name] = m.Format(value)
+	}
+
+	return formatted
+}
+
+func (m *Metric) quantile() float64 {
+	if m.Kind == MetricKindMedian {
+		return medianQuantile
+	}
+
+	return m.Quantile
+}
+
+// metricExpression returns sql expression of metric, NULL if metric is computed by fallback.
+func (r *SQLRepository) metricExpression(m *Metric) string {
+	expression, ok := r.dialect.metricExpression(m)
+	if !ok {
+		return "NULL"
+	}
+
+	return expression
+}
+
+// nullableMetric returns true if metric with name has Metric.Nullable.
+func (r *SQLRepository) nullableMetric(name string) bool {
+	m := r.getMetric(name)
+
+	return m != nil && m.Nullable
+}
+
+// metricValue returns scanned value of metric, NULL, NaN and infinite values of nullable metric are
+// returned as NullValueNumber, such values of other metrics are zero.
+func (r *SQLRepository) metricValue(name string, value interface{}) ValueNumber {
+	value = driverValue(unwrapPointerInterface(value))
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+
+	f, err := toFloat64(value)
+	if !r.nullableMetric(name) {
+		if err != nil {
+			return validValue(castValueNumber(value))
+		}
+
+		return validValue(ValueNumber(f))
+	}
+
+	if value == nil || err != nil {
+		return NullValueNumber()
+	}
+
+	return ValueNumber(f)
+}
+
+// fallbackMetrics returns metrics of request without native support of dialect.
+func (r *SQLRepository) fallbackMetrics(req *ItemsRequest) []*Metric {
+	metrics := make([]*Metric, 0)
+
+	for _, m := range r.selectedMetrics(req) {
+		if _, ok := r.dialect.metricExpression(m); !ok {
+			metrics = append(metrics, m)
+		}
+	}
+
+	return metrics
+}
+
+// checkOrder returns ErrUnsortableMetric if request is sorted by metric without native support of dialect.
+func (r *SQLRepository) checkOrder(req *ItemsRequest) error {
+	keys := make([]string, 0, len(req.SortBy)+1)
+
+	for _, order := range req.SortBy {
+		keys = append(keys, order.Key)
+	}
+
+	if req.Top != nil {
+		keys = append(keys, req.Top.Metric)
+	}
+
+	for _, key := range keys {
+		m := r.getMetric(key)
+		if m == nil {
+			continue
+		}
+
+		if _, ok := r.dialect.metricExpression(m); !ok {
+			return fmt.Errorf("%w: %q is computed without native support of dialect", ErrUnsortableMetric, key)
+		}
+	}
+
+	return nil
+}
+
+// buildFallback returns query of raw values of columns of metrics of groups of rows.
+func (r *SQLRepository) buildFallback(
+	req *ItemsRequest,
+	groups []string,
+	metrics []*Metric,
+	response []*ItemRow,
+) (string, []interface{}) {
+	query := ""
+	params := make([]interface{}, 0)
+
+	columns := make([]string, 0, len(groups)+len(metrics))
+
+	for _, group := range groups {
+		dim, _ := r.getDimension(DimensionKey(group))
+		columns = append(columns, dim.Expression)
+	}
+
+	for _, m := range metrics {
+		columns = append(columns, m.Column)
+	}
+
+	query += "SELECT " + strings.Join(columns, ",")
+	r.applyFrom(req, &query)
+
+	conditions := r.whereConditions(req, &params)
+	if condition := r.fallbackCondition(groups, response, &params); len(condition) > 0 {
+		conditions = append(conditions, condition)
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	return r.dialect.rebind(query), params
+}
+
+// fallbackCondition returns condition which matches raw rows of groups of response only,
+// so values of other groups are not selected.
+func (r *SQLRepository) fallbackCondition(groups []string, response []*ItemRow, params *[]interface{}) string {
+	if len(groups) == 0 {
+		return ""
+	}
+
+	seen := make(map[string]bool, len(response))
+	conditions := make([]string, 0, len(response))
+
+	for _, row := range response {
+		key := make([]interface{}, len(groups))
+		for i := range groups {
+			key[i] = row.Dimensions[groups[i]]
+		}
+
+		if seen[fallbackKey(key)] {
+			continue
+		}
+
+		seen[fallbackKey(key)] = true
+
+		condition := make([]string, len(groups))
+
+		for i, group := range groups {
+			dim, _ := r.getDimension(DimensionKey(group))
+
+			if key[i] == nil {
+				condition[i] = dim.Expression + " IS NULL"
+
+				continue
+			}
+
+			*params = append(*params, key[i])
+			condition[i] = dim.Expression + " = ?"
+		}
+
+		conditions = append(conditions, "("+strings.Join(condition, " AND ")+")")
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// applyFallbackMetrics computes metrics without native support of dialect in Go
+// from raw values of columns of groups of response and sets them to rows.
+func (r *SQLRepository) applyFallbackMetrics(ctx context.Context, req *ItemsRequest, response []*ItemRow) error {
+	metrics := r.fallbackMetrics(req)
+	if len(metrics) == 0 || len(response) == 0 {
+		return nil
+	}
+
+	groups := make([]string, 0, len(req.Groups))
+
+	for _, group := range req.Groups {
+		if _, ok := r.getDimension(DimensionKey(group)); ok {
+			groups = append(groups, group)
+		}
+	}
+
+	// rows of page are already selected by cursor.
+	raw := *req
+	raw.Cursor = nil
+	raw.after = nil
+
+	query, params := r.buildFallback(&raw, groups, metrics, response)
+
+	rows, err := r.conn.QueryContext(ctx, query, params...)
+	if err != nil {
+		return fmt.Errorf("failed to exec query: %w, query: %s, params: %v", err, query, params)
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string][][]float64)
+
+	for rows.Next() {
+		dest := makeDestFromTypes(types)
+		r.applyNullableDest(types, groups, dest)
+
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+
+		key := make([]interface{}, len(groups))
+		for i := range groups {
+			key[i] = r.normalizeDimension(groups[i], unwrapPointerInterface(dest[i]))
+		}
+
+		k := fallbackKey(key)
+		if _, ok := values[k]; !ok {
+			values[k] = make([][]float64, len(metrics))
+		}
+
+		for i := range metrics {
+			value := driverValue(unwrapPointerInterface(dest[len(groups)+i]))
+			if value == nil {
+				continue
+			}
+
+			if v, err := toFloat64(value); err == nil {
+				values[k][i] = append(values[k][i], v)
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, row := range response {
+		key := make([]interface{}, len(groups))
+		for i := range groups {
+			key[i] = row.Dimensions[groups[i]]
+		}
+
+		grouped := values[fallbackKey(key)]
+
+		for i, m := range metrics {
+			var v []float64
+			if grouped != nil {
+				v = grouped[i]
+			}
+
+			if len(v) == 0 && m.Nullable {
+				row.Metrics[m.Name] = NullValueNumber()
+
+				continue
+			}
+
+			row.Metrics[m.Name] = ValueNumber(quantile(v, m.quantile()))
+		}
+	}
+
+	return nil
+}
+
+func fallbackKey(key []interface{}) string {
+	parts := make([]string, len(key))
+	for i := range key {
+		parts[i] = dictionaryKey(key[i])
+	}
+
+	return strings.Join(parts, "\x00")
+}
+
+// quantile returns quantile q of values with linear interpolation, zero for empty values.
+func quantile(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+
+	if lower < 0 {
+		return sorted[0]
+	}
+
+	if upper >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
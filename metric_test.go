@@ -0,0 +1,213 @@
+package statistica
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect_MetricExpression(t *testing.T) {
+	t.Parallel()
+
+	p95 := &Metric{Name: "p95", Kind: MetricKindQuantile, Column: "latency", Quantile: 0.95}
+	median := &Metric{Name: "median", Kind: MetricKindMedian, Column: "price"}
+	users := &Metric{Name: "users", Kind: MetricKindDistinct, Column: "user_id"}
+	approx := &Metric{Name: "users", Kind: MetricKindApproxDistinct, Column: "user_id"}
+
+	tt := []struct {
+		name     string
+		dialect  Dialect
+		metric   *Metric
+		expected string
+		native   bool
+	}{
+		{name: "expression", metric: &Metric{Expression: "count(*)"}, expected: "count(*)", native: true},
+		{name: "clickhouse quantile", dialect: DialectClickHouse, metric: p95, expected: "quantile(0.95)(latency)", native: true},
+		{name: "clickhouse median", dialect: DialectClickHouse, metric: median, expected: "median(price)", native: true},
+		{name: "clickhouse distinct", dialect: DialectClickHouse, metric: users, expected: "uniqExact(user_id)", native: true},
+		{name: "clickhouse approx", dialect: DialectClickHouse, metric: approx, expected: "uniq(user_id)", native: true},
+		{
+			name:     "postgres quantile",
+			dialect:  DialectPostgres,
+			metric:   p95,
+			expected: "percentile_cont(0.95) WITHIN GROUP (ORDER BY latency)",
+			native:   true,
+		},
+		{
+			name:     "postgres median",
+			dialect:  DialectPostgres,
+			metric:   median,
+			expected: "percentile_cont(0.5) WITHIN GROUP (ORDER BY price)",
+			native:   true,
+		},
+		{name: "mysql approx", dialect: DialectMySQL, metric: approx, expected: "COUNT(DISTINCT user_id)", native: true},
+		{name: "sqlite distinct", dialect: DialectSQLite, metric: users, expected: "COUNT(DISTINCT user_id)", native: true},
+		{name: "sqlite quantile", dialect: DialectSQLite, metric: p95},
+		{name: "mysql median", dialect: DialectMySQL, metric: median},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			expression, native := tc.dialect.metricExpression(tc.metric)
+			require.Equal(t, tc.native, native)
+			require.Equal(t, tc.expected, expression)
+		})
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	t.Parallel()
+
+	values := []float64{5, 1, 4, 2, 3}
+
+	require.Equal(t, 3.0, quantile(values, 0.5))
+	require.Equal(t, 1.0, quantile(values, 0))
+	require.Equal(t, 5.0, quantile(values, 1))
+	require.InDelta(t, 4.8, quantile(values, 0.95), 1e-9)
+	require.Equal(t, 2.5, quantile([]float64{1, 2, 3, 4}, 0.5))
+	require.Equal(t, 0.0, quantile(nil, 0.5))
+	require.Equal(t, []float64{5, 1, 4, 2, 3}, values)
+}
+
+func TestSQLRepository_FallbackMetrics(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}},
+		[]*Metric{
+			{Name: "users", Kind: MetricKindDistinct, Column: "user_id"},
+			{Name: "median", Kind: MetricKindMedian, Column: "price"},
+		},
+		DialectSQLRepositoryOption(DialectSQLite),
+	)
+
+	mock.
+		ExpectQuery(
+			"^"+regexp.QuoteMeta(
+				"SELECT geo_id, COUNT(DISTINCT user_id) AS users,NULL AS median FROM test_table "+
+					"WHERE geo_id IN (?,?) GROUP BY geo_id")+"$",
+		).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "users", "median"}).
+			AddRow(int64(1), int64(2), nil).
+			AddRow(int64(2), int64(1), nil))
+
+	mock.
+		ExpectQuery("^"+regexp.QuoteMeta(
+			"SELECT geo_id,price FROM test_table WHERE geo_id IN (?,?) AND ((geo_id = ?) OR (geo_id = ?))")+"$").
+		WithArgs(1, 2, int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "price"}).
+			AddRow(int64(1), 10.0).
+			AddRow(int64(1), 30.0).
+			AddRow(int64(1), nil).
+			AddRow(int64(2), int64(7)))
+
+	rows, err := r.Grouped(&ItemsRequest{
+		Groups:  []string{"geo_id"},
+		Filters: []*ItemsRequestFilter{{Key: "geo_id", Values: []interface{}{1, 2}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]ValueNumber{"users": 2, "median": 20}, rows[0].Metrics)
+	require.Equal(t, map[string]ValueNumber{"users": 1, "median": 7}, rows[1].Metrics)
+
+	mock.
+		ExpectQuery("^" + regexp.QuoteMeta("SELECT geo_id, COUNT(DISTINCT user_id) AS users FROM test_table GROUP BY geo_id") + "$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "users"}).AddRow(int64(1), int64(2)))
+
+	rows, err = r.Grouped(&ItemsRequest{Groups: []string{"geo_id"}, Metrics: []string{"users"}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]ValueNumber{"users": 2}, rows[0].Metrics)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = r.Grouped(&ItemsRequest{
+		Groups: []string{"geo_id"},
+		SortBy: []*ItemsRequestOrder{{Key: "median", Direction: "DESC"}},
+	})
+	require.True(t, errors.Is(err, ErrUnsortableMetric))
+}
+
+func TestSQLRepository_FallbackMetricsSubqueryTable(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, "(SELECT * FROM events WHERE deleted = 0) AS e",
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}},
+		[]*Metric{{Name: "median", Kind: MetricKindMedian, Column: "price"}},
+		DialectSQLRepositoryOption(DialectSQLite),
+	)
+
+	mock.ExpectQuery("GROUP BY geo_id$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "median"}).AddRow(int64(1), nil))
+	mock.
+		ExpectQuery("^" + regexp.QuoteMeta(
+			"SELECT geo_id,price FROM (SELECT * FROM events WHERE deleted = 0) AS e WHERE ((geo_id = ?))") + "$").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "price"}).AddRow(int64(1), 10.0))
+
+	rows, err := r.Grouped(&ItemsRequest{Groups: []string{"geo_id"}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]ValueNumber{"median": 10}, rows[0].Metrics)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMetric_Format(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name     string
+		metric   *Metric
+		value    ValueNumber
+		expected string
+	}{
+		{name: "default", metric: &Metric{}, value: 12.6, expected: "12.6"},
+		{name: "ratio", metric: &Metric{}, value: 0.04, expected: "0.04"},
+		{name: "integer", metric: &Metric{Precision: MetricPrecision(0)}, value: 12.6, expected: "13"},
+		{name: "null", metric: &Metric{Precision: MetricPrecision(2)}, value: NullValueNumber(), expected: ""},
+		{name: "count", metric: &Metric{Unit: MetricUnitCount}, value: 42, expected: "42"},
+		{
+			name:     "cents",
+			metric:   &Metric{Unit: MetricUnitCurrency, Currency: "USD", Scale: 0.01, Precision: MetricPrecision(2)},
+			value:    1250,
+			expected: "12.50 USD",
+		},
+		{name: "currency without code", metric: &Metric{Unit: MetricUnitCurrency, Precision: MetricPrecision(2)}, value: 3, expected: "3.00"},
+		{name: "percent of ratio", metric: &Metric{Unit: MetricUnitPercent, Scale: 100, Precision: MetricPrecision(1)}, value: 0.0421, expected: "4.2%"},
+		{name: "seconds", metric: &Metric{Unit: MetricUnitSeconds, Precision: MetricPrecision(3)}, value: 0.25, expected: "0.250s"},
+		{name: "bytes", metric: &Metric{Unit: MetricUnitBytes}, value: 1024, expected: "1024 B"},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.expected, tc.metric.Format(tc.value))
+		})
+	}
+}
+
+func TestFormatMetrics(t *testing.T) {
+	t.Parallel()
+
+	metrics := []*Metric{
+		{Name: "cost", Unit: MetricUnitCurrency, Currency: "EUR", Scale: 0.01, Precision: MetricPrecision(2), Direction: MetricDirectionLower},
+	}
+
+	require.Equal(t,
+		map[string]string{"cost": "10.05 EUR", "total": "1.5"},
+		FormatMetrics(metrics, map[string]ValueNumber{"cost": 1005, "total": 1.5}),
+	)
+}
@@ -0,0 +1,186 @@
+package statistica
+
+import (
+	"context"
+	"sync"
+)
+
+// pageTotalColumn alias of count of groups selected by window function with rows of page.
+const pageTotalColumn = "statistica_total"
+
+// Page returns rows, total count of groups and summary of metrics across all groups by query ItemsRequest.
+func (r *SQLRepository) Page(req *ItemsRequest) (*ItemsResponse, error) {
+	return r.PageContext(context.Background(), req)
+}
+
+// PageContext returns rows, total count of groups and summary of metrics across all groups by query ItemsRequest.
+// Queries of rows, total and summary are executed concurrently, total is selected with rows by
+// window function if dialect supports it.
+func (r *SQLRepository) PageContext(ctx context.Context, req *ItemsRequest) (*ItemsResponse, error) {
+	ctx, o := r.observe(ctx, MethodPage, req)
+	response, err := r.page(ctx, o, req)
+
+	rows := 0
+	if response != nil {
+		rows = len(response.Rows)
+	}
+
+	o.end(ctx, rows, err)
+
+	return response, err
+}
+
+// page returns response of Page, query of rows is recorded by observation of call,
+// concurrent queries of summary and total are measured with it.
+func (r *SQLRepository) page(ctx context.Context, o *observation, req *ItemsRequest) (*ItemsResponse, error) {
+	response := &ItemsResponse{}
+	windowTotal := r.windowPageTotal(req)
+	totalFound := false
+
+	tasks := []func(ctx context.Context) error{
+		func(ctx context.Context) error {
+			var err error
+
+			if windowTotal {
+				response.Rows, response.Total, totalFound, err = r.pageGrouped(ctx, o, req)
+			} else {
+				response.Rows, err = r.grouped(ctx, o, req)
+			}
+
+			return err
+		},
+		func(ctx context.Context) error {
+			var err error
+
+			response.Summary, err = r.summaryRow(ctx, o.detached(), req)
+
+			return err
+		},
+	}
+
+	if !windowTotal {
+		tasks = append(tasks, func(ctx context.Context) error {
+			total, err := r.total(ctx, o.detached(), req)
+			response.Total = ValueNumber(total)
+
+			return err
+		})
+	}
+
+	if err := concurrently(ctx, tasks...); err != nil {
+		return nil, err
+	}
+
+	// page out of rows has no count of groups.
+	if windowTotal && !totalFound {
+		total, err := r.total(ctx, o.detached(), req)
+		if err != nil {
+			return nil, err
+		}
+
+		response.Total = ValueNumber(total)
+	}
+
+	response.NextCursor = NextCursor(req, response.Rows)
+
+	return response, nil
+}
+
+// windowPageTotal returns true if count of groups is selected with rows of page by window function.
+func (r *SQLRepository) windowPageTotal(req *ItemsRequest) bool {
+	// window function after seek condition of cursor counts only groups after cursor.
+	afterCursor := req.Cursor != nil && req.Cursor.After != ""
+
+	return r.dialect.windows() && req.Top == nil && !req.Totals && !afterCursor && r.exactGroupsTotal(req)
+}
+
+// pageGrouped returns rows of page and count of groups selected by window function,
+// false if count is unknown because page has no rows.
+func (r *SQLRepository) pageGrouped(
+	ctx context.Context,
+	o *observation,
+	req *ItemsRequest,
+) ([]*ItemRow, ValueNumber, bool, error) {
+	if err := r.checkWindows(req); err != nil {
+		return nil, 0, false, err
+	}
+
+	if err := r.checkOrder(req); err != nil {
+		return nil, 0, false, err
+	}
+
+	req, err := r.seekRequest(req)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	req, err = r.prepareRequest(req, true)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	rows, err := r.groupedRows(ctx, o, req, (*SQLRepository).buildPage)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	total := ValueNumber(0)
+
+	for _, row := range rows {
+		total = row.Metrics[pageTotalColumn]
+		delete(row.Metrics, pageTotalColumn)
+	}
+
+	return rows, total, len(rows) > 0 || req.Offset <= 0, nil
+}
+
+// buildPage returns query of grouped rows with count of all groups of request.
+func (r *SQLRepository) buildPage(req *ItemsRequest) (string, []interface{}) {
+	query := ""
+	params := make([]interface{}, 0)
+
+	r.applySelect(req, &query)
+	// window function is computed before LIMIT, so it counts all groups.
+	query += ", count(*) OVER () AS " + pageTotalColumn
+	r.applyFrom(req, &query)
+	query += " "
+	r.applyWhere(req, &query, &params)
+	r.applyGroup(req, &query)
+	r.applyHaving(req, &query, &params)
+	r.applyOrder(req, &query)
+	r.applyLimit(req, &query)
+
+	return r.dialect.rebind(query), params
+}
+
+// concurrently runs tasks in goroutines and returns first error, context of other tasks is canceled on error.
+func concurrently(ctx context.Context, tasks ...func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	for i := range tasks {
+		wg.Add(1)
+
+		go func(task func(ctx context.Context) error) {
+			defer wg.Done()
+
+			if err := task(ctx); err != nil {
+				once.Do(func() {
+					firstErr = err
+
+					cancel()
+				})
+			}
+		}(tasks[i])
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
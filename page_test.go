@@ -0,0 +1,152 @@
+package statistica
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func testPageRepository(t *testing.T, dialect Dialect) (*SQLRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	mock.MatchExpectationsInOrder(false)
+
+	return NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}},
+		[]*Metric{{Name: "total", Expression: "count(*)"}, {Name: "cost", Expression: "sum(price)"}},
+		DialectSQLRepositoryOption(dialect),
+	), mock
+}
+
+func TestSQLRepository_PageWindowTotal(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testPageRepository(t, DialectPostgres)
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT geo_id, count(*) AS total,sum(price) AS cost, count(*) OVER () AS statistica_total "+
+					"FROM test_table WHERE geo_id NOT IN ($1) GROUP BY geo_id ORDER BY cost DESC LIMIT 2") + "$",
+		).
+		WithArgs(0).
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total", "cost", "statistica_total"}).
+			AddRow(int64(1), int64(3), int64(30), int64(5)).
+			AddRow(int64(2), int64(2), int64(20), int64(5)))
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT count(*) AS total,sum(price) AS cost FROM test_table WHERE geo_id NOT IN ($1)") + "$",
+		).
+		WithArgs(0).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "cost"}).AddRow(int64(9), int64(70)))
+
+	page, err := r.Page(&ItemsRequest{
+		Limit:   2,
+		Groups:  []string{"geo_id"},
+		SortBy:  []*ItemsRequestOrder{{Key: "cost", Direction: "DESC"}},
+		Filters: []*ItemsRequestFilter{{Key: "geo_id", Condition: CondNotEq, Values: []interface{}{0}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Rows, 2)
+	require.Equal(t, map[string]ValueNumber{"total": 3, "cost": 30}, page.Rows[0].Metrics)
+	require.Equal(t, ValueNumber(5), page.Total)
+	require.Equal(t, RowTypeTotal, page.Summary.Type)
+	require.Equal(t, map[string]ValueNumber{"total": 9, "cost": 70}, page.Summary.Metrics)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepository_PageOutOfRows(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testPageRepository(t, DialectSQLite)
+
+	mock.ExpectQuery("count\\(\\*\\) OVER \\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total", "cost", "statistica_total"}))
+	mock.ExpectQuery("^SELECT count\\(\\*\\) AS total,sum\\(price\\) AS cost FROM test_table$").
+		WillReturnRows(sqlmock.NewRows([]string{"total", "cost"}).AddRow(int64(9), int64(70)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) AS total FROM (SELECT 1 FROM test_table GROUP BY geo_id) AS grouped")).
+		WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(int64(5)))
+
+	page, err := r.Page(&ItemsRequest{Limit: 10, Offset: 10, Groups: []string{"geo_id"}})
+	require.NoError(t, err)
+	require.Empty(t, page.Rows)
+	require.Equal(t, ValueNumber(5), page.Total)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepository_PageConcurrentTotal(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testPageRepository(t, DialectGeneric)
+
+	mock.ExpectQuery("^SELECT geo_id, count\\(\\*\\) AS total,sum\\(price\\) AS cost FROM test_table GROUP BY geo_id$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total", "cost"}).AddRow(int64(1), int64(9), int64(70)))
+	mock.ExpectQuery("^SELECT count\\(\\*\\) AS total,sum\\(price\\) AS cost FROM test_table$").
+		WillReturnRows(sqlmock.NewRows([]string{"total", "cost"}).AddRow(int64(9), int64(70)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) AS total FROM (SELECT 1 FROM test_table GROUP BY geo_id) AS grouped")).
+		WillReturnError(errors.New("connection lost"))
+
+	_, err := r.Page(&ItemsRequest{Groups: []string{"geo_id"}})
+	require.ErrorContains(t, err, "connection lost")
+}
+
+func TestSQLRepository_PageObservedOnce(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	mock.MatchExpectationsInOrder(false)
+
+	records := make(chan *AuditRecord, 10)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}},
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+		DialectSQLRepositoryOption(DialectPostgres),
+		GuardrailsSQLRepositoryOption(&Guardrails{MaxLimit: 10}),
+		AuditSQLRepositoryOption(ChanAuditSink(records), 0),
+	)
+
+	pageQuery := "SELECT geo_id, count(*) AS total, count(*) OVER () AS statistica_total " +
+		"FROM test_table WHERE lower(CAST(geo_id AS TEXT)) LIKE $1 ESCAPE '\\' GROUP BY geo_id LIMIT 5"
+
+	mock.ExpectQuery("^" + regexp.QuoteMeta(pageQuery) + "$").
+		WithArgs("1%").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total", "statistica_total"}).AddRow(int64(1), int64(3), int64(1)))
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT count(*) AS total FROM test_table WHERE lower(CAST(geo_id AS TEXT)) LIKE $1 ESCAPE '\\'") + "$",
+		).
+		WithArgs("1%").
+		WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(int64(3)))
+
+	req := &ItemsRequest{Limit: 5, Groups: []string{"geo_id"}, Search: &SearchRequest{Term: "1"}}
+
+	page, err := r.Page(req)
+	require.NoError(t, err)
+	require.Len(t, page.Rows, 1)
+	require.Equal(t, ValueNumber(1), page.Total)
+	require.Equal(t, ValueNumber(3), page.Summary.Metrics["total"])
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.Len(t, records, 1)
+	record := <-records
+	require.Equal(t, MethodPage, record.Method)
+	require.Contains(t, record.Query, pageTotalColumn)
+
+	plan, err := r.Explain(MethodPage, req)
+	require.NoError(t, err)
+	require.Equal(t, MethodPage, plan.Method)
+	require.Equal(t, record.Query, plan.Query)
+	require.Equal(t, record.Params, plan.Params)
+}
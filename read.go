@@ -1,12 +1,16 @@
 package statistica
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"math"
 	"reflect"
 	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -18,22 +22,58 @@ type ReadRepository interface {
 	Values(req *ItemsRequest) ([]*ValueResponse, error)
 	// Grouped returns rows metrics by group filtered by query conditions.
 	Grouped(req *ItemsRequest) ([]*ItemRow, error)
+	// Page returns rows metrics by group with total count of groups and summary of metrics.
+	Page(req *ItemsRequest) (*ItemsResponse, error)
 	// Metrics returns list of allowed metrics.
 	Metrics() ([]*Metric, error)
+	// Dimensions returns list of allowed dimensions.
+	Dimensions() ([]*Dimension, error)
+	// Hierarchies returns list of hierarchies of allowed dimensions.
+	Hierarchies() ([]*Hierarchy, error)
+
+	// TotalContext returns total rows by query conditions.
+	TotalContext(ctx context.Context, req *ItemsRequest) (uint64, error)
+	// ValuesContext returns list of allowed values with size by query conditions.
+	ValuesContext(ctx context.Context, req *ItemsRequest) ([]*ValueResponse, error)
+	// GroupedContext returns rows metrics by group filtered by query conditions.
+	GroupedContext(ctx context.Context, req *ItemsRequest) ([]*ItemRow, error)
+	// PageContext returns rows metrics by group with total count of groups and summary of metrics.
+	PageContext(ctx context.Context, req *ItemsRequest) (*ItemsResponse, error)
 }
 
 // SQLRepository sql implementation of ReadRepository.
 type SQLRepository struct {
 	conn *sql.DB
 
+	dimensions    []*Dimension
 	mapDimensions map[DimensionKey]*Dimension
 	metrics       []*Metric
+	hierarchies   []*Hierarchy
+
+	aggregates []*AggregateTable
+	// contains expression of count of rows, count(*) by default.
+	count string
 
 	// contains table name or sql expression like table.
 	table string
 
 	// contains name column for total value.
 	totalColumnName string
+	// enables approximate count of groups in Total.
+	approximateTotal bool
+
+	guardrails *Guardrails
+
+	auditSink      AuditSink
+	auditThreshold time.Duration
+
+	dialect Dialect
+
+	decimalEncoding DecimalEncoding
+
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry
 
 	logger *zap.Logger
 }
@@ -60,6 +100,7 @@ func NewSQLRepository(
 	r := &SQLRepository{
 		conn:          connection,
 		table:         table,
+		dimensions:    dimensions,
 		mapDimensions: mDimensions,
 		metrics:       metrics,
 		logger:        zap.NewNop(),
@@ -69,6 +110,15 @@ func NewSQLRepository(
 		options[i](r)
 	}
 
+	t, err := newTelemetry(r.tracerProvider, r.meterProvider)
+	if err != nil {
+		r.logger.Warn("failed to init telemetry", zap.Error(err))
+
+		t = newNoopTelemetry()
+	}
+
+	r.telemetry = t
+
 	return r
 }
 
@@ -77,6 +127,11 @@ func (r *SQLRepository) Metrics() ([]*Metric, error) {
 	return r.metrics, nil
 }
 
+// Dimensions returns allowed dimensions.
+func (r *SQLRepository) Dimensions() ([]*Dimension, error) {
+	return r.dimensions, nil
+}
+
 func makeDestFromTypes(types []*sql.ColumnType) []interface{} {
 	dest := make([]interface{}, len(types))
 
@@ -92,20 +147,52 @@ func makeDestFromTypes(types []*sql.ColumnType) []interface{} {
 	return dest
 }
 
+// applyNullableDest sets interface{} destinations for nullable columns of metrics and typed dimensions,
+// so NULL is kept as nil instead of zero value. Columns of dimensions without type are scanned by type of driver.
+func (r *SQLRepository) applyNullableDest(types []*sql.ColumnType, groups []string, dest []interface{}) {
+	for i, item := range types {
+		if nullable, ok := item.Nullable(); !ok || !nullable {
+			continue
+		}
+
+		if i < len(groups) {
+			if dim, exists := r.getDimension(DimensionKey(groups[i])); !exists || dim.Type == "" {
+				continue
+			}
+		}
+
+		dest[i] = new(interface{})
+	}
+}
+
 // Total returns total rows by query ItemsRequest.
 func (r *SQLRepository) Total(req *ItemsRequest) (uint64, error) {
+	return r.TotalContext(context.Background(), req)
+}
+
+// TotalContext returns total rows by query ItemsRequest.
+func (r *SQLRepository) TotalContext(ctx context.Context, req *ItemsRequest) (uint64, error) {
+	ctx, o := r.observe(ctx, MethodTotal, req)
+	total, err := r.total(ctx, o, req)
+	o.end(ctx, 1, err)
+
+	return total, err
+}
+
+func (r *SQLRepository) total(ctx context.Context, o *observation, req *ItemsRequest) (uint64, error) {
 	r.logger.Debug("request", zap.Reflect("request", req))
 
-	query := ""
-	params := make([]interface{}, 0)
+	req, err := r.prepareRequest(req, false)
+	if err != nil {
+		return 0, err
+	}
 
-	r.applySelectTotal(req, &query)
-	query += fmt.Sprintf(" FROM %s", r.table)
-	r.applyWhere(req, &query, &params)
+	query, params := r.route(MethodTotal, req).buildTotal(req)
+	o.query(req, query, params)
 
 	r.logger.Debug("total query SQL", zap.String("query", query))
 
-	rows, err := r.conn.Query(query, params...)
+	rows, err := r.conn.QueryContext(ctx, query, params...)
 	if err != nil {
 		return 0, err
 	}
@@ -136,6 +223,10 @@ func (r *SQLRepository) Total(req *ItemsRequest) (uint64, error) {
 			if total, ok := castInt64(value); ok {
 				return uint64(total), nil
 			}
+
+			if total, ok := castFloat64(value); ok {
+				return uint64(total), nil
+			}
 		}
 	}
 
@@ -148,119 +239,149 @@ func (r *SQLRepository) Total(req *ItemsRequest) (uint64, error) {
 
 // Values returns values ValueResponse by query ItemsRequest.
 func (r *SQLRepository) Values(req *ItemsRequest) ([]*ValueResponse, error) {
-	query := ""
-	params := make([]interface{}, 0)
-
-	r.applySelectValue(req, &query)
-	query += fmt.Sprintf(" FROM %s ", r.table)
-	r.applyWhere(req, &query, &params)
-	r.applyGroup(req, &query)
-	r.applyOrder(req, &query)
-	r.applyLimit(req, &query)
+	return r.ValuesContext(context.Background(), req)
+}
 
-	rows, err := r.conn.Query(query, params...)
+// ValuesContext returns values ValueResponse by query ItemsRequest.
+func (r *SQLRepository) ValuesContext(ctx context.Context, req *ItemsRequest) ([]*ValueResponse, error) {
+	it, err := r.ValuesIterator(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to exec query: %w, query: %s, params: %v", err, query, params)
+		return nil, err
 	}
-	defer rows.Close()
 
-	types, err := rows.ColumnTypes()
+	return it.collect()
+}
+
+// Grouped returns rows ItemRow by query ItemsRequest.
+func (r *SQLRepository) Grouped(req *ItemsRequest) ([]*ItemRow, error) {
+	return r.GroupedContext(context.Background(), req)
+}
+
+// GroupedContext returns rows ItemRow by query ItemsRequest.
+func (r *SQLRepository) GroupedContext(ctx context.Context, req *ItemsRequest) ([]*ItemRow, error) {
+	it, err := r.GroupedIterator(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	response := make([]*ValueResponse, 0)
-
-	for rows.Next() {
-		dest := makeDestFromTypes(types)
+	return it.collect()
+}
 
-		if err := rows.Scan(dest...); err != nil {
-			return nil, err
-		}
+func (r *SQLRepository) grouped(ctx context.Context, o *observation, req *ItemsRequest) ([]*ItemRow, error) {
+	it, err := r.groupedIterator(ctx, o, req)
+	if err != nil {
+		return nil, err
+	}
 
-		itemResp := &ValueResponse{
-			Key:   make([]interface{}, len(req.Groups)),
-			Name:  make([]interface{}, len(req.Groups)),
-			Count: 0,
-		}
+	return it.collect()
+}
 
-		for i := range types {
-			if len(req.Groups) > i {
-				itemResp.Name[i] = req.Groups[i]
-				itemResp.Key[i] = unwrapPointerInterface(dest[i])
-			} else {
-				itemResp.Count = castValueNumber(dest[i])
-			}
-		}
+// groupedRows returns rows of prepared request by query which is built by routed repository.
+func (r *SQLRepository) groupedRows(
+	ctx context.Context,
+	o *observation,
+	req *ItemsRequest,
+	build func(*SQLRepository, *ItemsRequest) (string, []interface{}),
+) ([]*ItemRow, error) {
+	it, err := r.queryGrouped(ctx, o, req, build)
+	if err != nil {
+		return nil, err
+	}
 
-		response = append(response, itemResp)
+	response, err := it.collect()
+	if err != nil {
+		return nil, err
 	}
 
-	if err := rows.Err(); err != nil {
+	if err := it.routed.applyFallbackMetrics(ctx, req, response); err != nil {
 		return nil, err
 	}
 
+	it.routed.applyWindowMetrics(req, response)
+
 	return response, nil
 }
 
-func (r *SQLRepository) Grouped(req *ItemsRequest) ([]*ItemRow, error) {
-	r.logger.Debug("request ItemsRequest", zap.Reflect("request", req))
-
-	query := ""
-	params := make([]interface{}, 0)
-
-	r.applySelect(req, &query)
-	query += fmt.Sprintf(" FROM %s ", r.table)
-	r.applyWhere(req, &query, &params)
-	r.applyGroup(req, &query)
-	r.applyOrder(req, &query)
-	r.applyLimit(req, &query)
-
-	r.logger.Debug("grouped query", zap.String("query", query))
+// prepareRequest returns copy of request with resolved labels, coerced filter values and applied guardrails.
+func (r *SQLRepository) prepareRequest(req *ItemsRequest, limited bool) (*ItemsRequest, error) {
+	if err := r.checkSearch(req); err != nil {
+		return nil, err
+	}
 
-	rows, err := r.conn.Query(query, params...)
+	req, err := r.resolveLabels(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to exec query: %w, query: %s, params: %v", err, query, params)
+		return nil, err
 	}
-	defer rows.Close()
 
-	types, err := rows.ColumnTypes()
+	req, err = r.coerceFilters(req)
 	if err != nil {
 		return nil, err
 	}
 
-	r.logger.Debug("types", zap.Reflect("types", types))
-
-	response := make([]*ItemRow, 0)
+	return r.applyGuardrails(req, limited)
+}
 
-	for rows.Next() {
-		dest := makeDestFromTypes(types)
+func (r *SQLRepository) buildTotal(req *ItemsRequest) (string, []interface{}) {
+	query := ""
+	params := make([]interface{}, 0)
 
-		if err := rows.Scan(dest...); err != nil {
-			return nil, err
-		}
+	if req.Cursor != nil {
+		// total counts all groups of request, not groups after cursor.
+		all := *req
+		all.Cursor = nil
+		all.after = nil
+		req = &all
+	}
 
-		itemResp := &ItemRow{
-			Dimensions: make(map[string]interface{}),
-			Metrics:    make(map[string]ValueNumber),
-		}
+	if r.exactGroupsTotal(req) {
+		// count of groups is count of rows of grouped query on every dialect.
+		query += "SELECT 1"
+		r.applyFrom(req, &query)
+		query += " "
+		r.applyWhere(req, &query, &params)
+		r.applyGroup(req, &query)
 
-		for i := range types {
-			if len(req.Groups) > i {
-				itemResp.Dimensions[req.Groups[i]] = unwrapPointerInterface(dest[i])
-			} else {
-				itemResp.Metrics[types[i].Name()] = castValueNumber(dest[i])
-			}
-		}
+		query = fmt.Sprintf("SELECT count(*) AS %s FROM (%s) AS grouped", r.getTotalColumnName(), query)
 
-		response = append(response, itemResp)
+		return r.dialect.rebind(query), params
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
+	r.applySelectTotal(req, &query)
+	r.applyFrom(req, &query)
+	r.applyWhere(req, &query, &params)
 
-	return response, nil
+	return r.dialect.rebind(query), params
+}
+
+func (r *SQLRepository) buildValues(req *ItemsRequest) (string, []interface{}) {
+	query := ""
+	params := make([]interface{}, 0)
+
+	r.applySelectValue(req, &query)
+	r.applyFrom(req, &query)
+	query += " "
+	r.applyWhere(req, &query, &params)
+	r.applyGroup(req, &query)
+	r.applyOrder(req, &query)
+	r.applyLimit(req, &query)
+
+	return r.dialect.rebind(query), params
+}
+
+func (r *SQLRepository) buildGrouped(req *ItemsRequest) (string, []interface{}) {
+	query := ""
+	params := make([]interface{}, 0)
+
+	r.applySelect(req, &query)
+	r.applyFrom(req, &query)
+	query += " "
+	r.applyWhere(req, &query, &params)
+	r.applyGroup(req, &query)
+	r.applyHaving(req, &query, &params)
+	r.applyOrder(req, &query)
+	r.applyLimit(req, &query)
+
+	return r.dialect.rebind(query), params
 }
 
 func (r *SQLRepository) applyGroup(req *ItemsRequest, query *string) {
@@ -283,15 +404,29 @@ func (r *SQLRepository) applyGroup(req *ItemsRequest, query *string) {
 func (r *SQLRepository) applyOrder(req *ItemsRequest, query *string) {
 	sortBy := make([]string, 0)
 
-	if len(req.SortBy) > 0 {
-		for _, item := range req.SortBy {
-			field, exists := r.getDimension(DimensionKey(item.Key))
-			if !exists {
+	order := req.SortBy
+	if req.Cursor != nil {
+		order = cursorOrder(req)
+	}
+
+	if len(order) > 0 {
+		for _, item := range order {
+			if field, exists := r.getDimension(DimensionKey(item.Key)); exists {
+				sortBy = append(sortBy, fmt.Sprintf("%s %s", field.Expression, item.Direction))
+
 				continue
 			}
 
-			sort := fmt.Sprintf("%s %s", field.Expression, item.Direction)
-			sortBy = append(sortBy, sort)
+			if item.Key == ValueCountKey {
+				sortBy = append(sortBy, fmt.Sprintf("%s %s", r.countExpression(), item.Direction))
+
+				continue
+			}
+
+			// metrics are sorted by alias of select.
+			if r.getMetric(item.Key) != nil || (getWindow(req, item.Key) != nil && r.pushdownWindow(getWindow(req, item.Key))) {
+				sortBy = append(sortBy, fmt.Sprintf("%s %s", item.Key, item.Direction))
+			}
 		}
 	}
 
@@ -306,6 +441,16 @@ func (r *SQLRepository) Ping() error {
 	return err
 }
 
+func (r *SQLRepository) getMetric(name string) *Metric {
+	for _, m := range r.metrics {
+		if m.Name == name {
+			return m
+		}
+	}
+
+	return nil
+}
+
 func (r *SQLRepository) getDimension(key DimensionKey) (*Dimension, bool) {
 	if dim, ok := r.mapDimensions[key]; ok {
 		return dim, true
@@ -314,61 +459,163 @@ func (r *SQLRepository) getDimension(key DimensionKey) (*Dimension, bool) {
 	return nil, false
 }
 
-//nolint:cyclop
 func (r *SQLRepository) applyWhere(req *ItemsRequest, query *string, params *[]interface{}) {
-	where := ""
+	if conditions := r.whereConditions(req, params); len(conditions) > 0 {
+		*query += fmt.Sprintf(" WHERE %s", strings.Join(conditions, " AND "))
+	}
+}
 
-	if len(req.Filters) > 0 {
-		for _, filter := range req.Filters {
-			field, exists := r.getDimension(DimensionKey(filter.Key))
-			if !exists {
-				continue
-			}
+// whereConditions returns conditions of WHERE clause of filters, seek of cursor and search of request.
+func (r *SQLRepository) whereConditions(req *ItemsRequest, params *[]interface{}) []string {
+	conditions := make([]string, 0, len(req.Filters)+2)
+
+	for _, filter := range req.Filters {
+		field, exists := r.getDimension(DimensionKey(filter.Key))
+		if !exists {
+			continue
+		}
+
+		key := field.Expression
+		if len(key) == 0 {
+			continue
+		}
 
-			key := field.Expression
+		if condition := r.whereCondition(key, filter, params); len(condition) > 0 {
+			conditions = append(conditions, condition)
+		}
+	}
 
-			if len(key) > 0 && len(filter.Values) > 0 {
-				*params = append(*params, filter.Values...)
+	seek := make([]interface{}, 0)
+	if condition, having := r.seekCondition(req, &seek); len(condition) > 0 && !having {
+		conditions = append(conditions, condition)
+		*params = append(*params, seek...)
+	}
+
+	if condition := r.searchCondition(req, params); len(condition) > 0 {
+		conditions = append(conditions, condition)
+	}
+
+	return conditions
+}
+
+// whereCondition returns sql condition of filter, nil values of filter match NULL values of dimension.
+//
+//nolint:cyclop
+func (r *SQLRepository) whereCondition(key string, filter *ItemsRequestFilter, params *[]interface{}) string {
+	switch filter.Condition {
+	case CondIsNull:
+		return fmt.Sprintf("%s IS NULL", key)
+
+	case CondIsNotNull:
+		return fmt.Sprintf("%s IS NOT NULL", key)
+
+	case CondLabel:
+		if len(filter.Values) == 0 {
+			// label without values in dictionary matches nothing.
+			return "1 = 0"
+		}
+	}
 
-				if len(where) > 0 {
-					where += " AND "
-				}
+	if len(filter.Values) == 0 {
+		return ""
+	}
 
-				switch filter.Condition {
-				case CondEq, CondEq2:
-					in := strings.TrimRight(strings.Repeat("?,", len(filter.Values)), ",")
-					where += fmt.Sprintf("%s IN (%s)", key, in)
+	values, hasNull := splitNullValues(filter.Values)
 
-				case CondNotEq, CondNotEq2:
-					in := strings.Repeat("?,", len(filter.Values))
-					where += fmt.Sprintf("%s NOT IN (%s)", key, in)
+	switch filter.Condition {
+	case CondNotEq, CondNotEq2:
+		condition := ""
 
-				case CondLike:
-					where += fmt.Sprintf("%s LIKE '%s'", key, "%?%")
+		if len(values) > 0 {
+			*params = append(*params, values...)
+			condition = fmt.Sprintf("%s NOT IN (%s)", key, placeholders(len(values)))
+		}
 
-				case CondGreater:
-					where += fmt.Sprintf("%s > ?", key)
+		if hasNull {
+			if len(condition) > 0 {
+				condition += " AND "
+			}
 
-				case CondGreaterOrEq:
-					where += fmt.Sprintf("%s >= ?", key)
+			condition += fmt.Sprintf("%s IS NOT NULL", key)
+		}
 
-				case CondLess:
-					where += fmt.Sprintf("%s < ?", key)
+		return condition
 
-				case CondLessOrEq:
-					where += fmt.Sprintf("%s <= ?", key)
+	case CondLike, CondPrefix:
+		conditions := make([]string, 0, len(values))
 
-				default:
-					in := strings.TrimRight(strings.Repeat("?,", len(filter.Values)), ",")
-					where += fmt.Sprintf(`%s IN (%s)`, key, in)
-				}
+		for _, value := range values {
+			pattern := escapeLike(fmt.Sprint(value)) + "%"
+			if filter.Condition == CondLike {
+				pattern = "%" + pattern
 			}
+
+			*params = append(*params, pattern)
+			conditions = append(conditions, r.dialect.like(key))
+		}
+
+		if len(conditions) == 1 {
+			return conditions[0]
 		}
+
+		return "(" + strings.Join(conditions, " OR ") + ")"
+
+	case CondGreater:
+		*params = append(*params, filter.Values...)
+
+		return fmt.Sprintf("%s > ?", key)
+
+	case CondGreaterOrEq:
+		*params = append(*params, filter.Values...)
+
+		return fmt.Sprintf("%s >= ?", key)
+
+	case CondLess:
+		*params = append(*params, filter.Values...)
+
+		return fmt.Sprintf("%s < ?", key)
+
+	case CondLessOrEq:
+		*params = append(*params, filter.Values...)
+
+		return fmt.Sprintf("%s <= ?", key)
 	}
 
-	if len(where) > 0 {
-		*query += fmt.Sprintf(" WHERE %s", where)
+	if !hasNull {
+		*params = append(*params, values...)
+
+		return fmt.Sprintf("%s IN (%s)", key, placeholders(len(values)))
+	}
+
+	if len(values) == 0 {
+		return fmt.Sprintf("%s IS NULL", key)
+	}
+
+	*params = append(*params, values...)
+
+	return fmt.Sprintf("(%s IN (%s) OR %s IS NULL)", key, placeholders(len(values)), key)
+}
+
+func placeholders(n int) string {
+	return strings.TrimRight(strings.Repeat("?,", n), ",")
+}
+
+// splitNullValues returns values without nil values and flag of nil value.
+func splitNullValues(values []interface{}) ([]interface{}, bool) {
+	result := make([]interface{}, 0, len(values))
+	hasNull := false
+
+	for i := range values {
+		if values[i] == nil {
+			hasNull = true
+
+			continue
+		}
+
+		result = append(result, values[i])
 	}
+
+	return result, hasNull
 }
 
 func (r *SQLRepository) applySelectTotal(req *ItemsRequest, query *string) {
@@ -384,13 +631,15 @@ func (r *SQLRepository) applySelectTotal(req *ItemsRequest, query *string) {
 			dimGroup = append(dimGroup, dim.Expression)
 		}
 
-		// @TODO: remove uniq
-		*query += fmt.Sprintf("SELECT uniq(%s) AS %s", strings.Join(dimGroup, ","), r.getTotalColumnName())
+		if len(dimGroup) > 0 {
+			expression, _ := r.dialect.approximateDistinct(dimGroup)
+			*query += fmt.Sprintf("SELECT %s AS %s", expression, r.getTotalColumnName())
 
-		return
+			return
+		}
 	}
 
-	*query += fmt.Sprintf("SELECT count(*) AS %s", r.getTotalColumnName())
+	*query += fmt.Sprintf("SELECT %s AS %s", r.countExpression(), r.getTotalColumnName())
 }
 
 func (r *SQLRepository) getTotalColumnName() string {
@@ -419,7 +668,7 @@ func (r *SQLRepository) applySelectValue(req *ItemsRequest, query *string) {
 		*query += strings.Join(dimGroup, ",") + ", "
 	}
 
-	*query += fmt.Sprintf("count(*) AS %s", r.getTotalColumnName())
+	*query += fmt.Sprintf("%s AS %s", r.countExpression(), r.getTotalColumnName())
 }
 
 func (r *SQLRepository) applySelect(req *ItemsRequest, query *string) {
@@ -440,20 +689,32 @@ func (r *SQLRepository) applySelect(req *ItemsRequest, query *string) {
 		*query += strings.Join(dimGroup, ",") + ", "
 	}
 
-	metrics := make([]string, 0, len(r.metrics))
-	for i := range r.metrics {
-		m := r.metrics[i]
-		metrics = append(metrics, m.Expression+` AS `+m.Name)
+	selected := r.selectedMetrics(req)
+	metrics := make([]string, 0, len(selected))
+
+	for _, m := range selected {
+		expression := r.metricExpression(m)
+		metrics = append(metrics, expression+` AS `+m.Name)
+
+		if exact, ok := r.dialect.exactExpression(expression); ok && m.Exact {
+			metrics = append(metrics, exact+` AS `+exactColumnPrefix+m.Name)
+		}
 	}
 
 	*query += strings.Join(metrics, ",")
+
+	r.applySelectWindows(req, query)
 }
 
 func (r *SQLRepository) applyLimit(req *ItemsRequest, query *string) {
-	if req.Limit > 0 && req.Offset > 0 {
-		*query += fmt.Sprintf(" LIMIT %d, %d", req.Offset, req.Limit)
-	} else if req.Limit > 0 {
-		*query += fmt.Sprintf(" LIMIT %d", req.Limit)
+	if req.Limit > 0 {
+		offset := req.Offset
+		if req.Cursor != nil && req.Cursor.After != "" {
+			// page after cursor starts by seek condition.
+			offset = 0
+		}
+
+		*query += r.dialect.limit(req.Limit, offset)
 	}
 }
 
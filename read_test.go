@@ -3,6 +3,8 @@ package statistica
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
+	"math"
 	"regexp"
 	"testing"
 
@@ -151,7 +153,8 @@ func TestRepository_Total(t *testing.T) {
 	mock.
 		ExpectQuery(
 			"^"+regexp.QuoteMeta(
-				"SELECT uniq(user_id,geo_id) AS total FROM test_table WHERE geo_id IN (?,?,?)")+"$",
+				"SELECT count(*) AS total FROM (SELECT 1 FROM test_table WHERE geo_id IN (?,?,?) "+
+					"GROUP BY user_id,geo_id) AS grouped")+"$",
 		).
 		WithArgs(1, 2, 4).
 		WillReturnRows(sqlmock.NewRows(result))
@@ -243,3 +246,129 @@ func TestRepository_Grouped(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, result, list)
 }
+
+func TestRepository_NullFilters(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name   string
+		filter *ItemsRequestFilter
+		where  string
+		args   []interface{}
+	}{
+		{
+			name:   "is null",
+			filter: &ItemsRequestFilter{Key: "geo_id", Condition: CondIsNull},
+			where:  "geo_id IS NULL",
+		},
+		{
+			name:   "is not null",
+			filter: &ItemsRequestFilter{Key: "geo_id", Condition: CondIsNotNull},
+			where:  "geo_id IS NOT NULL",
+		},
+		{
+			name:   "eq with null",
+			filter: &ItemsRequestFilter{Key: "geo_id", Values: []interface{}{1, nil}},
+			where:  "(geo_id IN (?) OR geo_id IS NULL)",
+			args:   []interface{}{1},
+		},
+		{
+			name:   "eq null",
+			filter: &ItemsRequestFilter{Key: "geo_id", Condition: CondEq, Values: []interface{}{nil}},
+			where:  "geo_id IS NULL",
+		},
+		{
+			name:   "not eq",
+			filter: &ItemsRequestFilter{Key: "geo_id", Condition: CondNotEq, Values: []interface{}{1, 2}},
+			where:  "geo_id NOT IN (?,?)",
+			args:   []interface{}{1, 2},
+		},
+		{
+			name:   "not eq with null",
+			filter: &ItemsRequestFilter{Key: "geo_id", Condition: CondNotEq, Values: []interface{}{1, nil}},
+			where:  "geo_id NOT IN (?) AND geo_id IS NOT NULL",
+			args:   []interface{}{1},
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, _, err := sqlmock.New()
+			require.NoError(t, err)
+
+			plan, err := testRepository(t, db).Explain(MethodTotal, &ItemsRequest{Filters: []*ItemsRequestFilter{tc.filter}})
+			require.NoError(t, err)
+			require.Equal(t, "SELECT count(*) AS total FROM test_table WHERE "+tc.where, plan.Query)
+			require.Equal(t, len(tc.args), len(plan.Params))
+
+			for j := range tc.args {
+				require.Equal(t, tc.args[j], plan.Params[j])
+			}
+		})
+	}
+}
+
+func TestRepository_Nulls(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id", Type: DimensionTypeInt, NullLabel: "unknown"}},
+		[]*Metric{
+			{Name: "total", Expression: "count(*)"},
+			{Name: "avg_price", Expression: "avg(price)", Nullable: true},
+			{Name: "max_price", Expression: "max(price)"},
+		},
+	)
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT geo_id, count(*) AS total,avg(price) AS avg_price,max(price) AS max_price FROM test_table "+
+					"WHERE (geo_id IN (?) OR geo_id IS NULL) GROUP BY geo_id") + "$",
+		).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total", "avg_price", "max_price"}).
+			AddRow(int64(1), int64(2), 1.5, int64(2)).
+			AddRow(nil, int64(1), nil, nil).
+			AddRow(int64(2), int64(1), math.NaN(), math.Inf(1)))
+
+	rows, err := r.Grouped(&ItemsRequest{
+		Groups:  []string{"geo_id"},
+		Filters: []*ItemsRequestFilter{{Key: "geo_id", Values: []interface{}{"1", nil}}},
+	})
+	require.NoError(t, err)
+	require.Nil(t, rows[0].Labels)
+	require.Nil(t, rows[1].Dimensions["geo_id"])
+	require.Equal(t, map[string]string{"geo_id": "unknown"}, rows[1].Labels)
+	require.False(t, rows[1].Metrics["avg_price"].Valid())
+	require.Equal(t, ValueNumber(0), rows[1].Metrics["max_price"])
+	require.False(t, rows[2].Metrics["avg_price"].Valid())
+	require.Equal(t, ValueNumber(0), rows[2].Metrics["max_price"])
+
+	data, err := json.Marshal(rows[1])
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{"Dimensions":{"geo_id":null},"Metrics":{"total":1,"avg_price":null,"max_price":0},"Labels":{"geo_id":"unknown"}}`,
+		string(data),
+	)
+}
+
+func TestValueNumber_JSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal([]ValueNumber{1.5, NullValueNumber(), ValueNumber(math.Inf(1))})
+	require.NoError(t, err)
+	require.Equal(t, `[1.5,null,null]`, string(data))
+
+	var values []ValueNumber
+	require.NoError(t, json.Unmarshal([]byte(`[2, null]`), &values))
+	require.Equal(t, ValueNumber(2), values[0])
+	require.False(t, values[1].Valid())
+}
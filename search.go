@@ -0,0 +1,209 @@
+package statistica
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SearchMode special type for represent how values are matched by search term.
+type SearchMode string
+
+const (
+	// SearchPrefix matches values which start with term, it is default mode.
+	SearchPrefix SearchMode = "prefix"
+	// SearchSubstring matches values which contain term.
+	SearchSubstring SearchMode = "substring"
+)
+
+// ErrTooManySearchKeys returns when search matches more labels of dictionary than could be searched in query.
+var ErrTooManySearchKeys = errors.New("too many labels match search")
+
+// maxSearchKeys max count of dictionary keys matched by search of one dimension in query.
+const maxSearchKeys = 1000
+
+// SearchRequest this struct represents case-insensitive search of values of groups of request.
+// Values are folded to lower case by database, SQLite folds ASCII letters only, labels of dictionaries
+// are folded with Unicode rules.
+type SearchRequest struct {
+	// Term contains searched text.
+	Term string
+
+	// Mode contains mode of matching, SearchPrefix by default.
+	Mode SearchMode
+
+	// Dimensions contains names of dimensions which values or labels are searched, groups of request by default.
+	Dimensions []string
+}
+
+// groupsSearch returns search of request bound to groups of request, so it matches same rows in request without groups.
+func groupsSearch(req *ItemsRequest) *SearchRequest {
+	if req.Search == nil || len(req.Search.Dimensions) > 0 {
+		return req.Search
+	}
+
+	search := *req.Search
+	search.Dimensions = req.Groups
+
+	return &search
+}
+
+// match returns true if text matches term case-insensitively.
+func (s *SearchRequest) match(text string) bool {
+	text, term := strings.ToLower(text), strings.ToLower(s.Term)
+	if s.Mode == SearchSubstring {
+		return strings.Contains(text, term)
+	}
+
+	return strings.HasPrefix(text, term)
+}
+
+// pattern returns LIKE pattern of term in lower case.
+func (s *SearchRequest) pattern() string {
+	pattern := escapeLike(strings.ToLower(s.Term)) + "%"
+	if s.Mode == SearchSubstring {
+		pattern = "%" + pattern
+	}
+
+	return pattern
+}
+
+// LabelSearcher is implemented by Dictionary which could return keys of labels matched by search.
+type LabelSearcher interface {
+	// SearchKeys returns dimension values which labels match search.
+	SearchKeys(search *SearchRequest) []interface{}
+}
+
+// SearchKeys returns dimension values which labels match search.
+func (d StaticDictionary) SearchKeys(search *SearchRequest) []interface{} {
+	keys := make([]string, 0)
+
+	for key, label := range d {
+		if search.match(label) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	result := make([]interface{}, len(keys))
+	for i := range keys {
+		result[i] = keys[i]
+	}
+
+	return result
+}
+
+// SearchKeys returns dimension values which labels match search.
+func (d *SQLDictionary) SearchKeys(search *SearchRequest) []interface{} {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.dictionary.SearchKeys(search)
+}
+
+// searchExpression returns expression of dimension converted to string in lower case,
+// lower of SQLite and generic SQL could convert ASCII letters only.
+func (d Dialect) searchExpression(expression string) string {
+	switch d {
+	case DialectClickHouse:
+		return fmt.Sprintf("lowerUTF8(toString(%s))", expression)
+	case DialectPostgres:
+		return fmt.Sprintf("lower(CAST(%s AS TEXT))", expression)
+	case DialectMySQL:
+		return fmt.Sprintf("LOWER(CAST(%s AS CHAR))", expression)
+	}
+
+	return fmt.Sprintf("lower(%s)", expression)
+}
+
+// searchDimensions returns names of dimensions which values or labels are searched.
+func searchDimensions(req *ItemsRequest) []string {
+	if len(req.Search.Dimensions) > 0 {
+		return req.Search.Dimensions
+	}
+
+	return req.Groups
+}
+
+// checkSearch returns error if search matches too many labels of dictionary of any searched dimension.
+func (r *SQLRepository) checkSearch(req *ItemsRequest) error {
+	if req.Search == nil || req.Search.Term == "" {
+		return nil
+	}
+
+	for _, key := range searchDimensions(req) {
+		dim, exists := r.getDimension(DimensionKey(key))
+		if !exists {
+			continue
+		}
+
+		searcher, ok := dim.Dictionary.(LabelSearcher)
+		if !ok {
+			continue
+		}
+
+		if count := len(searcher.SearchKeys(req.Search)); count > maxSearchKeys {
+			return fmt.Errorf("%w: %d labels of %s, max %d", ErrTooManySearchKeys, count, key, maxSearchKeys)
+		}
+	}
+
+	return nil
+}
+
+// searchCondition returns condition which matches rows with value or label of any searched dimension matched by search.
+func (r *SQLRepository) searchCondition(req *ItemsRequest, params *[]interface{}) string {
+	if req.Search == nil || req.Search.Term == "" {
+		return ""
+	}
+
+	groups := searchDimensions(req)
+	conditions := make([]string, 0, len(groups))
+
+	for _, group := range groups {
+		dim, exists := r.getDimension(DimensionKey(group))
+		if !exists {
+			continue
+		}
+
+		*params = append(*params, req.Search.pattern())
+		conditions = append(conditions, r.dialect.like(r.dialect.searchExpression(dim.Expression)))
+
+		searcher, ok := dim.Dictionary.(LabelSearcher)
+		if !ok {
+			continue
+		}
+
+		keys := make([]interface{}, 0)
+
+		for _, key := range searcher.SearchKeys(req.Search) {
+			if dim.Type != "" {
+				value, err := dim.Coerce(key)
+				if err != nil {
+					// key of dictionary which is not value of dimension matches nothing.
+					continue
+				}
+
+				key = value
+			}
+
+			keys = append(keys, key)
+		}
+
+		if len(keys) > 0 {
+			*params = append(*params, keys...)
+			conditions = append(conditions, fmt.Sprintf("%s IN (%s)", dim.Expression, placeholders(len(keys))))
+		}
+	}
+
+	if len(conditions) == 0 {
+		return ""
+	}
+
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
@@ -0,0 +1,147 @@
+package statistica
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func testSearchRepository(t *testing.T, dialect Dialect) (*SQLRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return NewSQLRepository(db, testTable,
+		[]*Dimension{
+			{Name: "domain", Expression: "domain"},
+			{
+				Name:       "event_type",
+				Expression: "etype",
+				Type:       DimensionTypeInt,
+				Dictionary: StaticDictionary{"100": "Impression", "101": "Click", "102": "Conversion"},
+			},
+		},
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+		DialectSQLRepositoryOption(dialect),
+	), mock
+}
+
+func TestSQLRepository_ValuesSearch(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testSearchRepository(t, DialectSQLite)
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT domain, count(*) AS total FROM test_table WHERE lower(domain) LIKE ? ESCAPE '\\' "+
+					"GROUP BY domain ORDER BY count(*) DESC LIMIT 10") + "$",
+		).
+		WithArgs(`%ex\_a%`).
+		WillReturnRows(sqlmock.NewRows([]string{"domain", "total"}).
+			AddRow("ex_ample.com", int64(10)).
+			AddRow("www.EX_A.org", int64(2)))
+
+	values, err := r.Values(&ItemsRequest{
+		Limit:  10,
+		Groups: []string{"domain"},
+		Search: &SearchRequest{Term: "EX_A", Mode: SearchSubstring},
+	})
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepository_SearchCondition(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		dialect Dialect
+		where   string
+		params  []interface{}
+	}{
+		{
+			dialect: DialectSQLite,
+			where:   `(lower(etype) LIKE ? ESCAPE '\' OR etype IN (?,?))`,
+			params:  []interface{}{"c%", int64(101), int64(102)},
+		},
+		{
+			dialect: DialectPostgres,
+			where:   `(lower(CAST(etype AS TEXT)) LIKE $1 ESCAPE '\' OR etype IN ($2,$3))`,
+			params:  []interface{}{"c%", int64(101), int64(102)},
+		},
+		{
+			dialect: DialectClickHouse,
+			where:   `(lowerUTF8(toString(etype)) LIKE ? OR etype IN (?,?))`,
+			params:  []interface{}{"c%", int64(101), int64(102)},
+		},
+		{
+			dialect: DialectMySQL,
+			where:   `(LOWER(CAST(etype AS CHAR)) LIKE ? OR etype IN (?,?))`,
+			params:  []interface{}{"c%", int64(101), int64(102)},
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(string(tc.dialect), func(t *testing.T) {
+			t.Parallel()
+
+			r, _ := testSearchRepository(t, tc.dialect)
+
+			plan, err := r.Explain(MethodTotal, &ItemsRequest{
+				Groups: []string{"event_type"},
+				Search: &SearchRequest{Term: "C"},
+			})
+			require.NoError(t, err)
+			require.Contains(t, plan.Query, " WHERE "+tc.where)
+			require.Equal(t, tc.params, plan.Params)
+		})
+	}
+}
+
+func TestStaticDictionary_SearchKeys(t *testing.T) {
+	t.Parallel()
+
+	d := StaticDictionary{"1": "United States", "2": "United Kingdom", "3": "Germany"}
+
+	require.Equal(t, []interface{}{"1", "2"}, d.SearchKeys(&SearchRequest{Term: "united"}))
+	require.Equal(t, []interface{}{"2"}, d.SearchKeys(&SearchRequest{Term: "KING", Mode: SearchSubstring}))
+	require.Empty(t, d.SearchKeys(&SearchRequest{Term: "king"}))
+}
+
+func TestSQLRepository_SearchTooManyKeys(t *testing.T) {
+	t.Parallel()
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+
+	dictionary := make(StaticDictionary, maxSearchKeys+10)
+	for i := 0; i < maxSearchKeys+10; i++ {
+		dictionary[strconv.Itoa(i)] = "label " + strconv.Itoa(i)
+	}
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "event_type", Expression: "etype", Dictionary: dictionary}},
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+	)
+
+	_, err = r.Explain(MethodTotal, &ItemsRequest{
+		Groups: []string{"event_type"},
+		Search: &SearchRequest{Term: "label"},
+	})
+	require.True(t, errors.Is(err, ErrTooManySearchKeys))
+
+	plan, err := r.Explain(MethodTotal, &ItemsRequest{
+		Groups: []string{"event_type"},
+		Search: &SearchRequest{Term: "label 1"},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Params, 122)
+}
@@ -0,0 +1,590 @@
+package statistica
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// StreamRepository is implemented by ReadRepository which could return rows while query is executed.
+type StreamRepository interface {
+	// ValuesEach calls fn for each allowed value while query is executed.
+	ValuesEach(ctx context.Context, req *ItemsRequest, fn func(value *ValueResponse) error) error
+	// GroupedEach calls fn for each row metrics by group while query is executed.
+	GroupedEach(ctx context.Context, req *ItemsRequest, fn func(row *ItemRow) error) error
+}
+
+// valuesEach calls fn for each value of repository, values are collected before fn is called
+// if repository does not implement StreamRepository.
+func valuesEach(ctx context.Context, repository ReadRepository, req *ItemsRequest, fn func(value *ValueResponse) error) error {
+	if stream, ok := repository.(StreamRepository); ok {
+		return stream.ValuesEach(ctx, req, fn)
+	}
+
+	values, err := repository.ValuesContext(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		if err := fn(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// groupedEach calls fn for each row of repository, rows are collected before fn is called
+// if repository does not implement StreamRepository.
+func groupedEach(ctx context.Context, repository ReadRepository, req *ItemsRequest, fn func(row *ItemRow) error) error {
+	if stream, ok := repository.(StreamRepository); ok {
+		return stream.GroupedEach(ctx, req, fn)
+	}
+
+	rows, err := repository.GroupedContext(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// streamRows this struct represents open rows of query which are scanned one by one.
+type streamRows struct {
+	ctx   context.Context
+	o     *observation
+	rows  *sql.Rows
+	types []*sql.ColumnType
+
+	count  int
+	err    error
+	closed bool
+}
+
+// next advances rows of query, rows are closed when they are ended or context is canceled.
+func (s *streamRows) next() bool {
+	if s.closed {
+		return false
+	}
+
+	if err := s.ctx.Err(); err != nil {
+		s.stop(err)
+
+		return false
+	}
+
+	if s.rows == nil || !s.rows.Next() {
+		if s.rows != nil {
+			s.err = s.rows.Err()
+		}
+
+		s.Close()
+
+		return false
+	}
+
+	return true
+}
+
+// stop closes rows with error of iteration.
+func (s *streamRows) stop(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+
+	s.Close()
+}
+
+// Err returns error of iteration, nil if rows are ended or closed by caller.
+func (s *streamRows) Err() error {
+	return s.err
+}
+
+// Close closes rows of query and returns error of iteration, it is safe to call Close several times.
+func (s *streamRows) Close() error {
+	if s.closed {
+		return s.err
+	}
+
+	s.closed = true
+
+	if s.rows != nil {
+		if err := s.rows.Close(); err != nil && s.err == nil {
+			s.err = err
+		}
+	}
+
+	if s.o != nil {
+		s.o.end(s.ctx, s.count, s.err)
+	}
+
+	return s.err
+}
+
+// RowIterator this struct represents rows ItemRow of Grouped which are returned one by one while query is executed.
+// Iterator must be closed, usage:
+//
+//	it, err := repository.GroupedIterator(ctx, req)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//
+//	for it.Next() {
+//		row := it.Row()
+//	}
+//
+//	return it.Err()
+type RowIterator struct {
+	streamRows
+
+	repository *SQLRepository
+	routed     *SQLRepository
+	req        *ItemsRequest
+
+	// buffered contains rows of request which are computed after all rows are selected.
+	buffered []*ItemRow
+	row      *ItemRow
+}
+
+// Next advances iterator to next row, returns false when rows are ended, failed or context is canceled.
+func (it *RowIterator) Next() bool {
+	it.row = nil
+
+	if it.buffered != nil && !it.closed {
+		if err := it.ctx.Err(); err != nil {
+			it.stop(err)
+
+			return false
+		}
+
+		if len(it.buffered) == 0 {
+			it.Close()
+
+			return false
+		}
+
+		it.row, it.buffered = it.buffered[0], it.buffered[1:]
+		it.count++
+
+		return true
+	}
+
+	if !it.next() {
+		return false
+	}
+
+	row, err := it.scan()
+	if err != nil {
+		it.stop(err)
+
+		return false
+	}
+
+	it.row = row
+	it.count++
+
+	return true
+}
+
+// Row returns current row of iterator.
+func (it *RowIterator) Row() *ItemRow {
+	return it.row
+}
+
+func (it *RowIterator) scan() (*ItemRow, error) {
+	types := it.types
+	req := it.req
+
+	dest := makeDestFromTypes(types)
+	it.repository.applyNullableDest(types, req.Groups, dest)
+	it.routed.applyExactDest(types, len(req.Groups), dest)
+
+	if err := it.rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	itemResp := &ItemRow{
+		Dimensions: make(map[string]interface{}),
+		Metrics:    make(map[string]ValueNumber),
+	}
+
+	for i := range types {
+		if len(req.Groups) > i {
+			itemResp.Dimensions[req.Groups[i]] = it.repository.normalizeDimension(req.Groups[i], unwrapPointerInterface(dest[i]))
+
+			if label, ok := it.repository.dimensionLabel(req.Groups[i], itemResp.Dimensions[req.Groups[i]]); ok {
+				if itemResp.Labels == nil {
+					itemResp.Labels = make(map[string]string)
+				}
+
+				itemResp.Labels[req.Groups[i]] = label
+			}
+		} else if exact, ok := dest[i].(*Decimal); ok {
+			name := strings.TrimPrefix(types[i].Name(), exactColumnPrefix)
+			separate := name != types[i].Name()
+
+			if exact.isNull() && it.routed.nullableMetric(name) {
+				if !separate {
+					itemResp.Metrics[name] = NullValueNumber()
+				}
+
+				continue
+			}
+
+			if !separate {
+				itemResp.Metrics[name] = ValueNumber(exact.Float64())
+			}
+
+			if itemResp.ExactMetrics == nil {
+				itemResp.ExactMetrics = make(map[string]Decimal)
+			}
+
+			itemResp.ExactMetrics[name] = *exact
+		} else {
+			itemResp.Metrics[types[i].Name()] = it.routed.metricValue(types[i].Name(), dest[i])
+		}
+	}
+
+	return itemResp, nil
+}
+
+// collect returns all rows of iterator and closes it.
+func (it *RowIterator) collect() ([]*ItemRow, error) {
+	response := make([]*ItemRow, 0)
+
+	for it.Next() {
+		response = append(response, it.Row())
+	}
+
+	if err := it.Close(); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ValueIterator this struct represents values ValueResponse of Values which are returned one by one
+// while query is executed. Iterator must be closed as RowIterator.
+type ValueIterator struct {
+	streamRows
+
+	repository *SQLRepository
+	req        *ItemsRequest
+
+	value *ValueResponse
+}
+
+// Next advances iterator to next value, returns false when values are ended, failed or context is canceled.
+func (it *ValueIterator) Next() bool {
+	it.value = nil
+
+	if !it.next() {
+		return false
+	}
+
+	value, err := it.scan()
+	if err != nil {
+		it.stop(err)
+
+		return false
+	}
+
+	it.value = value
+	it.count++
+
+	return true
+}
+
+// Value returns current value of iterator.
+func (it *ValueIterator) Value() *ValueResponse {
+	return it.value
+}
+
+func (it *ValueIterator) scan() (*ValueResponse, error) {
+	types := it.types
+	req := it.req
+
+	dest := makeDestFromTypes(types)
+	it.repository.applyNullableDest(types, req.Groups, dest)
+
+	if err := it.rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	itemResp := &ValueResponse{
+		Key:   make([]interface{}, len(req.Groups)),
+		Name:  make([]interface{}, len(req.Groups)),
+		Count: 0,
+	}
+
+	for i := range types {
+		if len(req.Groups) > i {
+			itemResp.Name[i] = req.Groups[i]
+			itemResp.Key[i] = it.repository.normalizeDimension(req.Groups[i], unwrapPointerInterface(dest[i]))
+
+			if label, ok := it.repository.dimensionLabel(req.Groups[i], itemResp.Key[i]); ok {
+				if itemResp.Label == nil {
+					itemResp.Label = make([]interface{}, len(req.Groups))
+				}
+
+				itemResp.Label[i] = label
+			}
+		} else {
+			itemResp.Count = castValueNumber(dest[i])
+		}
+	}
+
+	return itemResp, nil
+}
+
+// collect returns all values of iterator and closes it.
+func (it *ValueIterator) collect() ([]*ValueResponse, error) {
+	response := make([]*ValueResponse, 0)
+
+	for it.Next() {
+		response = append(response, it.Value())
+	}
+
+	if err := it.Close(); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GroupedIterator returns iterator of rows ItemRow by query ItemsRequest, rows are scanned while iterator is advanced.
+// Rows of requests with Top, Totals, fallback metrics or windows which are not supported by dialect
+// are computed from all rows, so they are selected before iterator is returned.
+func (r *SQLRepository) GroupedIterator(ctx context.Context, req *ItemsRequest) (*RowIterator, error) {
+	ctx, o := r.observe(ctx, MethodGrouped, req)
+
+	it, err := r.groupedIterator(ctx, o, req)
+	if err != nil {
+		o.end(ctx, 0, err)
+
+		return nil, err
+	}
+
+	it.o = o
+
+	return it, nil
+}
+
+// GroupedEach calls fn for each row ItemRow by query ItemsRequest, iteration is stopped by first error of fn
+// which is returned as is. Rows are limited by guardrails like rows of Grouped.
+func (r *SQLRepository) GroupedEach(ctx context.Context, req *ItemsRequest, fn func(row *ItemRow) error) error {
+	it, err := r.GroupedIterator(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for it.Next() {
+		if err := fn(it.Row()); err != nil {
+			// error of fn is not error of query, so call is measured as succeeded.
+			_ = it.Close()
+
+			return err
+		}
+	}
+
+	return it.Close()
+}
+
+func (r *SQLRepository) groupedIterator(ctx context.Context, o *observation, req *ItemsRequest) (*RowIterator, error) {
+	r.logger.Debug("request ItemsRequest", zap.Reflect("request", req))
+
+	if err := r.checkOrder(req); err != nil {
+		return nil, err
+	}
+
+	if req.Top != nil && req.Totals {
+		return nil, ErrTotalsWithTop
+	}
+
+	if err := checkCursor(req); err != nil {
+		return nil, err
+	}
+
+	if req.Top != nil {
+		rows, err := r.groupedTop(ctx, o, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return bufferedRows(ctx, rows), nil
+	}
+
+	if req.Totals {
+		rows, err := r.groupedTotals(ctx, o, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return bufferedRows(ctx, rows), nil
+	}
+
+	if err := r.checkWindows(req); err != nil {
+		return nil, err
+	}
+
+	req, err := r.seekRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err = r.prepareRequest(req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.route(MethodGrouped, req).bufferedMetrics(req) {
+		rows, err := r.groupedRows(ctx, o, req, (*SQLRepository).buildGrouped)
+		if err != nil {
+			return nil, err
+		}
+
+		return bufferedRows(ctx, rows), nil
+	}
+
+	return r.queryGrouped(ctx, o, req, (*SQLRepository).buildGrouped)
+}
+
+// queryGrouped returns iterator of rows of prepared request by query which is built by routed repository.
+func (r *SQLRepository) queryGrouped(
+	ctx context.Context,
+	o *observation,
+	req *ItemsRequest,
+	build func(*SQLRepository, *ItemsRequest) (string, []interface{}),
+) (*RowIterator, error) {
+	routed := r.route(MethodGrouped, req)
+	query, params := build(routed, req)
+	o.query(req, query, params)
+
+	r.logger.Debug("grouped query", zap.String("query", query))
+
+	rows, err := r.conn.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exec query: %w, query: %s, params: %v", err, query, params)
+	}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+
+		return nil, err
+	}
+
+	r.logger.Debug("types", zap.Reflect("types", types))
+
+	return &RowIterator{
+		streamRows: streamRows{ctx: ctx, rows: rows, types: types},
+		repository: r,
+		routed:     routed,
+		req:        req,
+	}, nil
+}
+
+// bufferedMetrics returns true if metrics of request are computed from all rows after query.
+func (r *SQLRepository) bufferedMetrics(req *ItemsRequest) bool {
+	if len(r.fallbackMetrics(req)) > 0 {
+		return true
+	}
+
+	for _, w := range req.Windows {
+		if !r.pushdownWindow(w) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bufferedRows returns iterator of rows which are already selected.
+func bufferedRows(ctx context.Context, rows []*ItemRow) *RowIterator {
+	return &RowIterator{
+		streamRows: streamRows{ctx: ctx},
+		buffered:   rows,
+	}
+}
+
+// ValuesIterator returns iterator of values ValueResponse by query ItemsRequest,
+// values are scanned while iterator is advanced.
+func (r *SQLRepository) ValuesIterator(ctx context.Context, req *ItemsRequest) (*ValueIterator, error) {
+	ctx, o := r.observe(ctx, MethodValues, req)
+
+	it, err := r.valuesIterator(ctx, o, req)
+	if err != nil {
+		o.end(ctx, 0, err)
+
+		return nil, err
+	}
+
+	it.o = o
+
+	return it, nil
+}
+
+// ValuesEach calls fn for each value ValueResponse by query ItemsRequest, iteration is stopped by first error of fn
+// which is returned as is. Values are limited by guardrails like values of Values.
+func (r *SQLRepository) ValuesEach(ctx context.Context, req *ItemsRequest, fn func(value *ValueResponse) error) error {
+	it, err := r.ValuesIterator(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for it.Next() {
+		if err := fn(it.Value()); err != nil {
+			// error of fn is not error of query, so call is measured as succeeded.
+			_ = it.Close()
+
+			return err
+		}
+	}
+
+	return it.Close()
+}
+
+func (r *SQLRepository) valuesIterator(ctx context.Context, o *observation, req *ItemsRequest) (*ValueIterator, error) {
+	if req.Search != nil && len(req.SortBy) == 0 {
+		ranked := *req
+		ranked.SortBy = []*ItemsRequestOrder{{Key: ValueCountKey, Direction: "DESC"}}
+		req = &ranked
+	}
+
+	req, err := r.prepareRequest(req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	query, params := r.route(MethodValues, req).buildValues(req)
+	o.query(req, query, params)
+
+	rows, err := r.conn.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exec query: %w, query: %s, params: %v", err, query, params)
+	}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+
+		return nil, err
+	}
+
+	return &ValueIterator{
+		streamRows: streamRows{ctx: ctx, rows: rows, types: types},
+		repository: r,
+		req:        req,
+	}, nil
+}
@@ -0,0 +1,196 @@
+package statistica
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func testStreamRepository(t *testing.T, dialect Dialect) (*SQLRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}},
+		[]*Metric{{Name: "cost", Expression: "sum(price)"}},
+		DialectSQLRepositoryOption(dialect),
+	), mock
+}
+
+func TestSQLRepository_GroupedIterator(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testStreamRepository(t, DialectPostgres)
+
+	mock.ExpectQuery("^SELECT geo_id, sum\\(price\\) AS cost FROM test_table GROUP BY geo_id$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "cost"}).
+			AddRow(int64(1), int64(30)).
+			AddRow(int64(2), int64(20))).
+		RowsWillBeClosed()
+
+	it, err := r.GroupedIterator(context.Background(), &ItemsRequest{Groups: []string{"geo_id"}})
+	require.NoError(t, err)
+
+	costs := make([]ValueNumber, 0)
+	for it.Next() {
+		costs = append(costs, it.Row().Metrics["cost"])
+	}
+
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close())
+	require.NoError(t, it.Close())
+	require.False(t, it.Next())
+	require.Equal(t, []ValueNumber{30, 20}, costs)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepository_GroupedEachStop(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	records := make(chan *AuditRecord, 1)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}},
+		[]*Metric{{Name: "cost", Expression: "sum(price)"}},
+		DialectSQLRepositoryOption(DialectPostgres),
+		AuditSQLRepositoryOption(ChanAuditSink(records), 0),
+	)
+
+	mock.ExpectQuery("^SELECT geo_id, sum\\(price\\) AS cost FROM test_table GROUP BY geo_id$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "cost"}).
+			AddRow(int64(1), int64(30)).
+			AddRow(int64(2), int64(20))).
+		RowsWillBeClosed()
+
+	errStop := errors.New("stop")
+	calls := 0
+
+	err = r.GroupedEach(context.Background(), &ItemsRequest{Groups: []string{"geo_id"}}, func(row *ItemRow) error {
+		calls++
+
+		return errStop
+	})
+	require.True(t, errors.Is(err, errStop))
+	require.Equal(t, 1, calls)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// error of callback is not recorded as error of query.
+	record := <-records
+	require.Empty(t, record.Error)
+}
+
+func TestSQLRepository_GroupedIteratorCanceled(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testStreamRepository(t, DialectPostgres)
+
+	mock.ExpectQuery("^SELECT geo_id, sum\\(price\\) AS cost FROM test_table GROUP BY geo_id$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "cost"}).
+			AddRow(int64(1), int64(30)).
+			AddRow(int64(2), int64(20))).
+		RowsWillBeClosed()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	it, err := r.GroupedIterator(ctx, &ItemsRequest{Groups: []string{"geo_id"}})
+	require.NoError(t, err)
+	require.True(t, it.Next())
+
+	cancel()
+
+	require.False(t, it.Next())
+	require.True(t, errors.Is(it.Err(), context.Canceled))
+	require.True(t, errors.Is(it.Close(), context.Canceled))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepository_GroupedIteratorBuffered(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testStreamRepository(t, DialectGeneric)
+
+	mock.ExpectQuery("^SELECT geo_id, sum\\(price\\) AS cost FROM test_table GROUP BY geo_id$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "cost"}).
+			AddRow(int64(1), int64(30)).
+			AddRow(int64(2), int64(10)))
+
+	rows := make([]*ItemRow, 0)
+
+	err := r.GroupedEach(context.Background(), &ItemsRequest{
+		Groups:  []string{"geo_id"},
+		Windows: []*WindowMetric{{Metric: "cost", Function: WindowShare}},
+	}, func(row *ItemRow) error {
+		rows = append(rows, row)
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, ValueNumber(0.75), rows[0].Metrics["cost_share"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepository_ValuesIterator(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testStreamRepository(t, DialectPostgres)
+
+	mock.ExpectQuery("^SELECT geo_id, count\\(\\*\\) AS total FROM test_table GROUP BY geo_id$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total"}).
+			AddRow(int64(1), int64(3)).
+			AddRow(int64(2), int64(1))).
+		RowsWillBeClosed()
+
+	it, err := r.ValuesIterator(context.Background(), &ItemsRequest{Groups: []string{"geo_id"}})
+	require.NoError(t, err)
+	defer it.Close()
+
+	counts := make([]ValueNumber, 0)
+	for it.Next() {
+		counts = append(counts, it.Value().Count)
+	}
+
+	require.NoError(t, it.Err())
+	require.Equal(t, []ValueNumber{3, 1}, counts)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type collectedRepository struct {
+	ReadRepository
+}
+
+func TestCatalog_GroupedEachCollected(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testStreamRepository(t, DialectPostgres)
+
+	mock.ExpectQuery("^SELECT geo_id, sum\\(price\\) AS cost FROM test_table GROUP BY geo_id$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "cost"}).
+			AddRow(int64(1), int64(30)).
+			AddRow(int64(2), int64(20)))
+
+	catalog := NewCatalog()
+	require.NoError(t, catalog.Register("events", "", collectedRepository{r}))
+
+	costs := make([]ValueNumber, 0)
+
+	err := catalog.GroupedEach(context.Background(), &ItemsRequest{Cube: "events", Groups: []string{"geo_id"}},
+		func(row *ItemRow) error {
+			costs = append(costs, row.Metrics["cost"])
+
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []ValueNumber{30, 20}, costs)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,153 @@
+package statistica
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+const instrumentationName = "github.com/vench/statistica"
+
+// TracerProviderSQLRepositoryOption sets OpenTelemetry tracer provider, global provider is used by default.
+func TracerProviderSQLRepositoryOption(provider trace.TracerProvider) SQLRepositoryOption {
+	return func(repository *SQLRepository) {
+		repository.tracerProvider = provider
+	}
+}
+
+// MeterProviderSQLRepositoryOption sets OpenTelemetry meter provider, global provider is used by default.
+func MeterProviderSQLRepositoryOption(provider metric.MeterProvider) SQLRepositoryOption {
+	return func(repository *SQLRepository) {
+		repository.meterProvider = provider
+	}
+}
+
+type telemetry struct {
+	tracer trace.Tracer
+
+	queries  metric.Int64Counter
+	errors   metric.Int64Counter
+	rows     metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+func newTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) (*telemetry, error) {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+
+	meter := meterProvider.Meter(instrumentationName)
+	t := &telemetry{
+		tracer: tracerProvider.Tracer(instrumentationName),
+	}
+
+	var err error
+
+	if t.queries, err = meter.Int64Counter("statistica.queries",
+		metric.WithDescription("Number of executed queries.")); err != nil {
+		return nil, err
+	}
+
+	if t.errors, err = meter.Int64Counter("statistica.errors",
+		metric.WithDescription("Number of failed queries.")); err != nil {
+		return nil, err
+	}
+
+	if t.rows, err = meter.Int64Counter("statistica.rows",
+		metric.WithDescription("Number of scanned rows.")); err != nil {
+		return nil, err
+	}
+
+	if t.duration, err = meter.Float64Histogram("statistica.query.duration",
+		metric.WithDescription("Duration of queries."), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func newNoopTelemetry() *telemetry {
+	t, _ := newTelemetry(tracenoop.NewTracerProvider(), metricnoop.NewMeterProvider())
+
+	return t
+}
+
+// observation this struct represents measurement of one call of repository method.
+type observation struct {
+	repository *SQLRepository
+	span       trace.Span
+	attrs      metric.MeasurementOption
+	started    time.Time
+
+	method  QueryMethod
+	request *ItemsRequest
+	sql     string
+	params  []interface{}
+}
+
+func (r *SQLRepository) observe(ctx context.Context, method QueryMethod, req *ItemsRequest) (context.Context, *observation) {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", r.dialect.System()),
+		attribute.String("statistica.table", r.table),
+		attribute.String("statistica.method", string(method)),
+	}
+
+	ctx, span := r.telemetry.tracer.Start(ctx, "SQLRepository."+string(method), trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(attribute.StringSlice("statistica.groups", req.Groups)),
+	)
+
+	return ctx, &observation{
+		repository: r,
+		span:       span,
+		attrs:      metric.WithAttributes(attrs...),
+		started:    time.Now(),
+		method:     method,
+		request:    req,
+	}
+}
+
+// query sets executed query and request after applied defaults.
+func (o *observation) query(req *ItemsRequest, query string, params []interface{}) {
+	o.request = req
+	o.sql = query
+	o.params = params
+}
+
+// detached returns observation of query which runs in the same call concurrently or after recorded query,
+// it is never ended, so call is measured and audited once.
+func (o *observation) detached() *observation {
+	return &observation{repository: o.repository, method: o.method}
+}
+
+func (o *observation) end(ctx context.Context, rows int, err error) {
+	t := o.repository.telemetry
+	duration := time.Since(o.started)
+
+	t.queries.Add(ctx, 1, o.attrs)
+	t.duration.Record(ctx, duration.Seconds(), o.attrs)
+
+	if err != nil {
+		t.errors.Add(ctx, 1, o.attrs)
+		o.span.RecordError(err)
+		o.span.SetStatus(codes.Error, err.Error())
+	} else {
+		t.rows.Add(ctx, int64(rows), o.attrs)
+		o.span.SetAttributes(attribute.Int("statistica.rows", rows))
+	}
+
+	o.span.End()
+
+	o.repository.audit(ctx, o, duration, rows, err)
+}
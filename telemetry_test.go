@@ -0,0 +1,75 @@
+package statistica
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSQLRepository_Telemetry(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	spans := tracetest.NewInMemoryExporter()
+	registry := prometheus.NewRegistry()
+	reader, err := otelprometheus.New(otelprometheus.WithRegisterer(registry), otelprometheus.WithoutScopeInfo())
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "user_id", Expression: "user_id"}},
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+		DialectSQLRepositoryOption(DialectClickHouse),
+		TracerProviderSQLRepositoryOption(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))),
+		MeterProviderSQLRepositoryOption(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "total"}).
+			AddRow(int64(1), int64(10)).
+			AddRow(int64(2), int64(20)))
+	mock.ExpectQuery("SELECT").WillReturnError(errors.New("connection lost"))
+
+	_, err = r.GroupedContext(context.Background(), &ItemsRequest{Groups: []string{"user_id"}})
+	require.NoError(t, err)
+
+	_, err = r.TotalContext(context.Background(), &ItemsRequest{})
+	require.Error(t, err)
+
+	ended := spans.GetSpans()
+	require.Len(t, ended, 2)
+
+	require.Equal(t, "SQLRepository.Grouped", ended[0].Name)
+	require.Contains(t, ended[0].Attributes, attribute.String("db.system", "clickhouse"))
+	require.Contains(t, ended[0].Attributes, attribute.String("statistica.table", testTable))
+	require.Contains(t, ended[0].Attributes, attribute.StringSlice("statistica.groups", []string{"user_id"}))
+	require.Contains(t, ended[0].Attributes, attribute.Int("statistica.rows", 2))
+
+	require.Equal(t, "SQLRepository.Total", ended[1].Name)
+	require.Equal(t, codes.Error, ended[1].Status.Code)
+
+	recorder := httptest.NewRecorder()
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).
+		ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := recorder.Body.String()
+	require.Regexp(t, regexp.MustCompile(`statistica_queries_total\{[^}]*statistica_method="Grouped"[^}]*\} 1`), body)
+	require.Regexp(t, regexp.MustCompile(`statistica_errors_total\{[^}]*statistica_method="Total"[^}]*\} 1`), body)
+	require.Regexp(t, regexp.MustCompile(`statistica_rows_total\{[^}]*statistica_method="Grouped"[^}]*\} 2`), body)
+	require.Regexp(t, regexp.MustCompile(`statistica_query_duration_seconds_count\{[^}]*\} 1`), body)
+}
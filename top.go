@@ -0,0 +1,225 @@
+package statistica
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTop returns when top request has no positive N or unknown metric.
+var ErrInvalidTop = errors.New("invalid top request")
+
+const (
+	defaultOtherLabel = "Other"
+	defaultTotalLabel = "Total"
+)
+
+// RowType special type for represent type of row.
+type RowType string
+
+const (
+	// RowTypeGroup row of group, it is default type.
+	RowTypeGroup RowType = ""
+	// RowTypeOther synthetic row which contains groups out of top.
+	RowTypeOther RowType = "other"
+	// RowTypeTotal synthetic row which contains all groups.
+	RowTypeTotal RowType = "total"
+	// RowTypeSubtotal synthetic row which contains groups with same values of leading dimensions.
+	RowTypeSubtotal RowType = "subtotal"
+)
+
+// MetricMerge special type for represent how values of metric of several groups are merged.
+type MetricMerge string
+
+const (
+	// MetricMergeNone values could not be merged, e.g. average or distinct count.
+	MetricMergeNone MetricMerge = ""
+	// MetricMergeSum value of merged groups is sum of values, e.g. count or sum.
+	MetricMergeSum MetricMerge = "sum"
+	// MetricMergeMin value of merged groups is minimum of values.
+	MetricMergeMin MetricMerge = "min"
+	// MetricMergeMax value of merged groups is maximum of values.
+	MetricMergeMax MetricMerge = "max"
+)
+
+// TopRequest this struct represents request of top groups by metric with row of other groups.
+type TopRequest struct {
+	// N contains count of top groups.
+	N int
+
+	// Metric contains name of metric which groups are sorted by in descending order.
+	Metric string
+
+	// OtherLabel contains label of row of other groups, "Other" by default.
+	OtherLabel string
+
+	// TotalLabel contains label of row of all groups, "Total" by default.
+	TotalLabel string
+}
+
+func (t *TopRequest) otherLabel() string {
+	if t.OtherLabel == "" {
+		return defaultOtherLabel
+	}
+
+	return t.OtherLabel
+}
+
+func (t *TopRequest) totalLabel() string {
+	if t.TotalLabel == "" {
+		return defaultTotalLabel
+	}
+
+	return t.TotalLabel
+}
+
+// groupedTop returns top groups, row of other groups if there are more groups than top and row of all groups.
+// Top and total are selected by two queries, values of other row are computed by Metric.Merge.
+func (r *SQLRepository) groupedTop(ctx context.Context, o *observation, req *ItemsRequest) ([]*ItemRow, error) {
+	metric := r.getMetric(req.Top.Metric)
+	if req.Top.N <= 0 || metric == nil {
+		return nil, fmt.Errorf("%w: top %d by %q", ErrInvalidTop, req.Top.N, req.Top.Metric)
+	}
+
+	top := *req
+	top.Top = nil
+	top.Cursor = nil
+	top.SortBy = []*ItemsRequestOrder{{Key: metric.Name, Direction: "DESC"}}
+	// one more row shows that there are groups out of top.
+	top.Limit = req.Top.N + 1
+	top.Offset = 0
+
+	if len(req.Metrics) > 0 {
+		top.Metrics = append(append(make([]string, 0, len(req.Metrics)+1), req.Metrics...), metric.Name)
+	}
+
+	rows, err := r.grouped(ctx, o, &top)
+	if err != nil {
+		return nil, err
+	}
+
+	totalRow, err := r.summaryRow(ctx, o, req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.labelSyntheticRow(req, totalRow, req.Top.totalLabel())
+
+	if len(rows) > req.Top.N {
+		rows = rows[:req.Top.N]
+		rows = append(rows, r.otherRow(req, rows, totalRow))
+	}
+
+	return append(rows, totalRow), nil
+}
+
+// summaryRow returns row of metrics of all groups of request, search of request still matches values of its groups.
+// Summary is one row, so limit of guardrails is not applied to it.
+func (r *SQLRepository) summaryRow(ctx context.Context, o *observation, req *ItemsRequest) (*ItemRow, error) {
+	summary := *req
+	summary.Groups = nil
+	summary.SortBy = nil
+	summary.Limit = 0
+	summary.Offset = 0
+	summary.Top = nil
+	summary.Totals = false
+	summary.Windows = nil
+	summary.Cursor = nil
+	summary.Search = groupsSearch(req)
+
+	prepared, err := r.prepareRequest(&summary, false)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.groupedRows(ctx, o, prepared, (*SQLRepository).buildGrouped)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &ItemRow{Metrics: make(map[string]ValueNumber)}
+	if len(rows) > 0 {
+		row = rows[0]
+	}
+
+	row.Type = RowTypeTotal
+
+	return row, nil
+}
+
+// otherRow returns row of groups which are not in rows, value of metric is difference of total
+// and rows for MetricMergeSum, total for MetricMergeMin and MetricMergeMax if total is not reached
+// by rows, NULL otherwise.
+func (r *SQLRepository) otherRow(req *ItemsRequest, rows []*ItemRow, totalRow *ItemRow) *ItemRow {
+	other := &ItemRow{
+		Metrics: make(map[string]ValueNumber, len(totalRow.Metrics)),
+		Type:    RowTypeOther,
+	}
+
+	r.labelSyntheticRow(req, other, req.Top.otherLabel())
+
+	for name, total := range totalRow.Metrics {
+		other.Metrics[name] = NullValueNumber()
+
+		metric := r.getMetric(name)
+		if metric == nil || !total.Valid() {
+			continue
+		}
+
+		switch metric.Merge {
+		case MetricMergeSum:
+			value := total
+			for _, row := range rows {
+				if row.Metrics[name].Valid() {
+					value -= row.Metrics[name]
+				}
+			}
+
+			other.Metrics[name] = value
+
+			if exact, ok := totalRow.ExactMetrics[name]; ok {
+				for _, row := range rows {
+					exact = exact.Sub(row.ExactMetrics[name])
+				}
+
+				if other.ExactMetrics == nil {
+					other.ExactMetrics = make(map[string]Decimal)
+				}
+
+				other.ExactMetrics[name] = exact
+			}
+
+		case MetricMergeMin, MetricMergeMax:
+			reached := false
+
+			for _, row := range rows {
+				if row.Metrics[name] == total {
+					reached = true
+
+					break
+				}
+			}
+
+			// total value is value of other groups if it is not value of rows.
+			if !reached {
+				other.Metrics[name] = total
+			}
+		}
+	}
+
+	return other
+}
+
+func (r *SQLRepository) labelSyntheticRow(req *ItemsRequest, row *ItemRow, label string) {
+	row.Dimensions = make(map[string]interface{}, len(req.Groups))
+	row.Labels = make(map[string]string, len(req.Groups))
+
+	for _, group := range req.Groups {
+		if _, ok := r.getDimension(DimensionKey(group)); !ok {
+			continue
+		}
+
+		row.Dimensions[group] = nil
+		row.Labels[group] = label
+	}
+}
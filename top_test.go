@@ -0,0 +1,147 @@
+package statistica
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func testTopRepository(t *testing.T) (*SQLRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}},
+		[]*Metric{
+			{Name: "total", Expression: "count(*)", Merge: MetricMergeSum},
+			{Name: "max_price", Expression: "max(price)", Merge: MetricMergeMax},
+			{Name: "min_price", Expression: "min(price)", Merge: MetricMergeMin},
+			{Name: "avg_price", Expression: "avg(price)"},
+		},
+	), mock
+}
+
+func TestSQLRepository_GroupedTop(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testTopRepository(t)
+
+	columns := []string{"geo_id", "total", "max_price", "min_price", "avg_price"}
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT geo_id, count(*) AS total,max(price) AS max_price,min(price) AS min_price,avg(price) AS avg_price "+
+					"FROM test_table WHERE geo_id NOT IN (?) GROUP BY geo_id ORDER BY total DESC LIMIT 3") + "$",
+		).
+		WithArgs(0).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(int64(1), int64(50), int64(100), int64(5), 20.0).
+			AddRow(int64(2), int64(30), int64(70), int64(1), 10.0).
+			AddRow(int64(3), int64(10), int64(40), int64(2), 15.0))
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT count(*) AS total,max(price) AS max_price,min(price) AS min_price,avg(price) AS avg_price "+
+					"FROM test_table WHERE geo_id NOT IN (?)") + "$",
+		).
+		WithArgs(0).
+		WillReturnRows(sqlmock.NewRows(columns[1:]).AddRow(int64(100), int64(120), int64(1), 17.0))
+
+	rows, err := r.Grouped(&ItemsRequest{
+		Groups:  []string{"geo_id"},
+		Filters: []*ItemsRequestFilter{{Key: "geo_id", Condition: CondNotEq, Values: []interface{}{0}}},
+		Top:     &TopRequest{N: 2, Metric: "total"},
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	require.Equal(t, int64(1), rows[0].Dimensions["geo_id"])
+	require.Equal(t, int64(2), rows[1].Dimensions["geo_id"])
+
+	other := rows[2]
+	require.Equal(t, RowTypeOther, other.Type)
+	require.Equal(t, map[string]interface{}{"geo_id": nil}, other.Dimensions)
+	require.Equal(t, map[string]string{"geo_id": "Other"}, other.Labels)
+	require.Equal(t, ValueNumber(20), other.Metrics["total"])
+	require.Equal(t, ValueNumber(120), other.Metrics["max_price"])
+	require.False(t, other.Metrics["min_price"].Valid())
+	require.False(t, other.Metrics["avg_price"].Valid())
+
+	total := rows[3]
+	require.Equal(t, RowTypeTotal, total.Type)
+	require.Equal(t, map[string]string{"geo_id": "Total"}, total.Labels)
+	require.Equal(t, ValueNumber(100), total.Metrics["total"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepository_GroupedTopWithoutOther(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testTopRepository(t)
+
+	mock.ExpectQuery("ORDER BY total DESC LIMIT 6").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total"}).AddRow(int64(1), int64(3)))
+	mock.ExpectQuery("FROM test_table$").
+		WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(int64(3)))
+
+	rows, err := r.Grouped(&ItemsRequest{
+		Groups:  []string{"geo_id"},
+		Metrics: []string{"avg_price"},
+		Top:     &TopRequest{N: 5, Metric: "total", TotalLabel: "All"},
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, RowTypeGroup, rows[0].Type)
+	require.Equal(t, map[string]string{"geo_id": "All"}, rows[1].Labels)
+
+	_, err = r.Grouped(&ItemsRequest{Groups: []string{"geo_id"}, Top: &TopRequest{N: 5, Metric: "unknown"}})
+	require.True(t, errors.Is(err, ErrInvalidTop))
+
+	_, err = r.Grouped(&ItemsRequest{Groups: []string{"geo_id"}, Top: &TopRequest{Metric: "total"}})
+	require.True(t, errors.Is(err, ErrInvalidTop))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepository_GroupedTopGuardrails(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	r := NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}},
+		[]*Metric{{Name: "total", Expression: "count(*)", Merge: MetricMergeSum}},
+		GuardrailsSQLRepositoryOption(&Guardrails{MaxLimit: 10}),
+	)
+
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta(
+				"SELECT geo_id, count(*) AS total FROM test_table WHERE lower(geo_id) LIKE ? ESCAPE '\\' "+
+					"GROUP BY geo_id ORDER BY total DESC LIMIT 3") + "$",
+		).
+		WithArgs("1%").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total"}).AddRow(int64(1), int64(3)))
+	mock.
+		ExpectQuery(
+			"^" + regexp.QuoteMeta("SELECT count(*) AS total FROM test_table WHERE lower(geo_id) LIKE ? ESCAPE '\\'") + "$",
+		).
+		WithArgs("1%").
+		WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(int64(3)))
+
+	rows, err := r.Grouped(&ItemsRequest{
+		Groups: []string{"geo_id"},
+		Search: &SearchRequest{Term: "1"},
+		Top:    &TopRequest{N: 2, Metric: "total"},
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, RowTypeTotal, rows[1].Type)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,45 @@
+package statistica
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ApproximateTotalSQLRepositoryOption enables approximate count of groups in Total on dialects which
+// support it, e.g. uniq of ClickHouse. Total returns exact count of groups by default.
+func ApproximateTotalSQLRepositoryOption() SQLRepositoryOption {
+	return func(repository *SQLRepository) {
+		repository.approximateTotal = true
+	}
+}
+
+// approximateDistinct returns expression of approximate count of distinct tuples of expressions,
+// false if dialect has no such function.
+func (d Dialect) approximateDistinct(expressions []string) (string, bool) {
+	if d == DialectClickHouse {
+		return fmt.Sprintf("uniq(%s)", strings.Join(expressions, ",")), true
+	}
+
+	return "", false
+}
+
+// exactGroupsTotal returns true if Total of request is count of rows of grouped subquery.
+func (r *SQLRepository) exactGroupsTotal(req *ItemsRequest) bool {
+	groups := false
+
+	for _, group := range req.Groups {
+		if _, ok := r.getDimension(DimensionKey(group)); ok {
+			groups = true
+
+			break
+		}
+	}
+
+	if !groups {
+		return false
+	}
+
+	_, ok := r.dialect.approximateDistinct(nil)
+
+	return !r.approximateTotal || !ok
+}
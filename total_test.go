@@ -0,0 +1,76 @@
+package statistica
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLRepository_TotalGroups(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name    string
+		options []SQLRepositoryOption
+		req     *ItemsRequest
+		query   string
+	}{
+		{
+			name:    "exact groups",
+			options: []SQLRepositoryOption{DialectSQLRepositoryOption(DialectSQLite)},
+			req:     &ItemsRequest{Groups: []string{"geo_id", "user_id"}},
+			query:   "SELECT count(*) AS total FROM (SELECT 1 FROM test_table  GROUP BY  geo_id,user_id) AS grouped",
+		},
+		{
+			name:    "exact groups on clickhouse",
+			options: []SQLRepositoryOption{DialectSQLRepositoryOption(DialectClickHouse)},
+			req:     &ItemsRequest{Groups: []string{"geo_id"}},
+			query:   "SELECT count(*) AS total FROM (SELECT 1 FROM test_table  GROUP BY  geo_id) AS grouped",
+		},
+		{
+			name: "approximate groups on clickhouse",
+			options: []SQLRepositoryOption{
+				DialectSQLRepositoryOption(DialectClickHouse),
+				ApproximateTotalSQLRepositoryOption(),
+			},
+			req:   &ItemsRequest{Groups: []string{"geo_id", "user_id"}},
+			query: "SELECT uniq(geo_id,user_id) AS total FROM test_table",
+		},
+		{
+			name: "approximate groups without support of dialect",
+			options: []SQLRepositoryOption{
+				DialectSQLRepositoryOption(DialectPostgres),
+				ApproximateTotalSQLRepositoryOption(),
+			},
+			req:   &ItemsRequest{Groups: []string{"geo_id"}},
+			query: "SELECT count(*) AS total FROM (SELECT 1 FROM test_table  GROUP BY  geo_id) AS grouped",
+		},
+		{
+			name:  "unknown groups",
+			req:   &ItemsRequest{Groups: []string{"unknown"}},
+			query: "SELECT count(*) AS total FROM test_table",
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, _, err := sqlmock.New()
+			require.NoError(t, err)
+
+			r := NewSQLRepository(db, testTable,
+				[]*Dimension{{Name: "geo_id", Expression: "geo_id"}, {Name: "user_id", Expression: "user_id"}},
+				[]*Metric{{Name: "total", Expression: "count(*)"}},
+				tc.options...,
+			)
+
+			plan, err := r.Explain(MethodTotal, tc.req)
+			require.NoError(t, err)
+			require.Equal(t, tc.query, plan.Query)
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package statistica
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+	"strings"
+)
+
+// ErrTotalsWithTop returns when request has both Totals and Top, row of all groups of top is its total.
+var ErrTotalsWithTop = errors.New("totals are not supported with top")
+
+// groupingColumn alias of bitmask of rolled up groups in query of subtotals.
+const groupingColumn = "statistica_grouping"
+
+// rollup returns GROUP BY clause of subtotals of dialect without rows of groups,
+// false if dialect has no support of ROLLUP.
+func (d Dialect) rollup(expressions []string) (string, bool) {
+	switch d {
+	case DialectPostgres, DialectClickHouse:
+		sets := make([]string, 0, len(expressions))
+		for level := len(expressions) - 1; level >= 0; level-- {
+			sets = append(sets, "("+strings.Join(expressions[:level], ",")+")")
+		}
+
+		return " GROUP BY GROUPING SETS (" + strings.Join(sets, ",") + ")", true
+	case DialectMySQL:
+		// MySQL has no GROUPING SETS, HAVING is applied after ROLLUP.
+		return " GROUP BY " + strings.Join(expressions, ",") + " WITH ROLLUP" +
+			" HAVING GROUPING(" + strings.Join(expressions, ",") + ") > 0", true
+	}
+
+	return "", false
+}
+
+// groupedTotals returns rows of groups followed by rows of subtotals from deepest level and row of grand total.
+// Limit and offset are applied to rows of groups only, subtotals are computed by all rows of request.
+// Subtotals are selected by one query with ROLLUP if dialect supports it, by query of each level otherwise.
+func (r *SQLRepository) groupedTotals(ctx context.Context, o *observation, req *ItemsRequest) ([]*ItemRow, error) {
+	leaf := *req
+	leaf.Totals = false
+
+	rows, err := r.grouped(ctx, o, &leaf)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]string, 0, len(req.Groups))
+
+	for _, group := range req.Groups {
+		if _, ok := r.getDimension(DimensionKey(group)); ok {
+			groups = append(groups, group)
+		}
+	}
+
+	if len(groups) == 0 {
+		for _, row := range rows {
+			row.Type = RowTypeTotal
+		}
+
+		return rows, nil
+	}
+
+	leaf.Groups = groups
+	leaf.Search = groupsSearch(req)
+	leaf.Windows = nil
+	leaf.Cursor = nil
+	leaf.Limit = 0
+	leaf.Offset = 0
+
+	var subtotals []*ItemRow
+
+	// metrics which are computed in Go could not be rolled up by database.
+	if _, ok := r.dialect.rollup(nil); ok && len(r.fallbackMetrics(&leaf)) == 0 {
+		subtotals, err = r.rollupSubtotals(ctx, o, &leaf)
+	} else {
+		subtotals, err = r.emulatedSubtotals(ctx, o, &leaf)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(rows, subtotals...), nil
+}
+
+// rollupSubtotals returns rows of subtotals selected by one query with ROLLUP, rows of groups are not selected.
+func (r *SQLRepository) rollupSubtotals(ctx context.Context, o *observation, req *ItemsRequest) ([]*ItemRow, error) {
+	prepared, err := r.prepareRequest(req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.groupedRows(ctx, o, prepared, (*SQLRepository).buildRollup)
+	if err != nil {
+		return nil, err
+	}
+
+	subtotals := make([]*ItemRow, 0, len(rows))
+
+	for _, row := range rows {
+		mask := uint64(row.Metrics[groupingColumn])
+		delete(row.Metrics, groupingColumn)
+
+		r.markSubtotal(req, row, len(req.Groups)-bits.OnesCount64(mask))
+		subtotals = append(subtotals, row)
+	}
+
+	return subtotals, nil
+}
+
+// emulatedSubtotals returns rows of subtotals selected by query of each level of groups.
+func (r *SQLRepository) emulatedSubtotals(ctx context.Context, o *observation, req *ItemsRequest) ([]*ItemRow, error) {
+	subtotals := make([]*ItemRow, 0)
+
+	for level := len(req.Groups) - 1; level >= 0; level-- {
+		sub := *req
+		sub.Groups = req.Groups[:level]
+		sub.SortBy = make([]*ItemsRequestOrder, 0, len(req.SortBy))
+
+		// sort by dimensions which are rolled up is not valid in query of level.
+		for _, item := range req.SortBy {
+			if r.getMetric(item.Key) != nil || inStrings(sub.Groups, item.Key) {
+				sub.SortBy = append(sub.SortBy, item)
+			}
+		}
+
+		prepared, err := r.prepareRequest(&sub, false)
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := r.groupedRows(ctx, o, prepared, (*SQLRepository).buildGrouped)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			r.markSubtotal(req, row, level)
+		}
+
+		subtotals = append(subtotals, rows...)
+	}
+
+	return subtotals, nil
+}
+
+// markSubtotal sets type of row and labels of groups which are rolled up from level.
+func (r *SQLRepository) markSubtotal(req *ItemsRequest, row *ItemRow, level int) {
+	row.Type = RowTypeSubtotal
+	if level == 0 {
+		row.Type = RowTypeTotal
+	}
+
+	if row.Labels == nil {
+		row.Labels = make(map[string]string, len(req.Groups)-level)
+	}
+
+	for _, group := range req.Groups[level:] {
+		row.Dimensions[group] = nil
+		row.Labels[group] = defaultTotalLabel
+	}
+}
+
+// buildRollup returns query of subtotals of groups, rows are ordered from deepest level.
+func (r *SQLRepository) buildRollup(req *ItemsRequest) (string, []interface{}) {
+	query := ""
+	params := make([]interface{}, 0)
+
+	expressions := make([]string, 0, len(req.Groups))
+
+	for _, group := range req.Groups {
+		dim, _ := r.getDimension(DimensionKey(group))
+		expressions = append(expressions, dim.Expression)
+	}
+
+	grouping := "GROUPING(" + strings.Join(expressions, ",") + ")"
+
+	r.applySelect(req, &query)
+	query += ", " + grouping + " AS " + groupingColumn
+	r.applyFrom(req, &query)
+	query += " "
+	r.applyWhere(req, &query, &params)
+
+	rollup, _ := r.dialect.rollup(expressions)
+	query += rollup
+
+	order := ""
+	r.applyOrder(req, &order)
+	query += " ORDER BY " + grouping
+
+	if order != "" {
+		query += "," + strings.TrimPrefix(order, " ORDER BY ")
+	}
+
+	return r.dialect.rebind(query), params
+}
+
+func inStrings(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,134 @@
+package statistica
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func testTotalsRepository(t *testing.T, dialect Dialect) (*SQLRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return NewSQLRepository(db, testTable,
+		[]*Dimension{{Name: "geo_id", Expression: "geo_id"}, {Name: "event_type", Expression: "event_type"}},
+		[]*Metric{{Name: "total", Expression: "count(*)"}},
+		DialectSQLRepositoryOption(dialect),
+	), mock
+}
+
+func testTotalsRequest() *ItemsRequest {
+	return &ItemsRequest{
+		Limit:  10,
+		Groups: []string{"geo_id", "event_type"},
+		SortBy: []*ItemsRequestOrder{{Key: "event_type", Direction: "ASC"}, {Key: "total", Direction: "DESC"}},
+		Totals: true,
+	}
+}
+
+func TestSQLRepository_GroupedTotalsRollup(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		dialect Dialect
+		rollup  string
+	}{
+		{dialect: DialectPostgres, rollup: "GROUP BY GROUPING SETS ((geo_id),())"},
+		{dialect: DialectMySQL, rollup: "GROUP BY geo_id,event_type WITH ROLLUP HAVING GROUPING(geo_id,event_type) > 0"},
+		{dialect: DialectClickHouse, rollup: "GROUP BY GROUPING SETS ((geo_id),())"},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(string(tc.dialect), func(t *testing.T) {
+			t.Parallel()
+
+			r, mock := testTotalsRepository(t, tc.dialect)
+
+			mock.
+				ExpectQuery(
+					"^" + regexp.QuoteMeta(
+						"SELECT geo_id,event_type, count(*) AS total FROM test_table GROUP BY geo_id,event_type "+
+							"ORDER BY event_type ASC,total DESC LIMIT 10") + "$",
+				).
+				WillReturnRows(sqlmock.NewRows([]string{"geo_id", "event_type", "total"}).
+					AddRow(int64(1), int64(100), int64(3)).
+					AddRow(int64(2), int64(100), int64(1)))
+
+			mock.
+				ExpectQuery(
+					"^" + regexp.QuoteMeta(
+						"SELECT geo_id,event_type, count(*) AS total, GROUPING(geo_id,event_type) AS statistica_grouping "+
+							"FROM test_table "+tc.rollup+" ORDER BY GROUPING(geo_id,event_type),event_type ASC,total DESC") + "$",
+				).
+				WillReturnRows(sqlmock.NewRows([]string{"geo_id", "event_type", "total", "statistica_grouping"}).
+					AddRow(int64(1), nil, int64(3), int64(1)).
+					AddRow(int64(2), nil, int64(1), int64(1)).
+					AddRow(nil, nil, int64(4), int64(3)))
+
+			rows, err := r.Grouped(testTotalsRequest())
+			require.NoError(t, err)
+			require.Len(t, rows, 5)
+
+			require.Equal(t, RowTypeGroup, rows[1].Type)
+
+			require.Equal(t, RowTypeSubtotal, rows[2].Type)
+			require.Equal(t, map[string]interface{}{"geo_id": int64(1), "event_type": nil}, rows[2].Dimensions)
+			require.Equal(t, map[string]string{"event_type": "Total"}, rows[2].Labels)
+			require.Equal(t, map[string]ValueNumber{"total": 3}, rows[2].Metrics)
+
+			require.Equal(t, RowTypeTotal, rows[4].Type)
+			require.Equal(t, map[string]interface{}{"geo_id": nil, "event_type": nil}, rows[4].Dimensions)
+			require.Equal(t, ValueNumber(4), rows[4].Metrics["total"])
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSQLRepository_GroupedTotalsEmulated(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testTotalsRepository(t, DialectSQLite)
+
+	mock.ExpectQuery("GROUP BY geo_id,event_type ORDER BY event_type ASC,total DESC LIMIT 10$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "event_type", "total"}).
+			AddRow(int64(1), int64(100), int64(3)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT geo_id, count(*) AS total FROM test_table GROUP BY geo_id ORDER BY total DESC") + "$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "total"}).
+			AddRow(int64(1), int64(3)).
+			AddRow(int64(2), int64(1)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) AS total FROM test_table ORDER BY total DESC") + "$").
+		WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(int64(4)))
+
+	rows, err := r.Grouped(testTotalsRequest())
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	require.Equal(t, RowTypeSubtotal, rows[1].Type)
+	require.Equal(t, map[string]interface{}{"geo_id": int64(1), "event_type": nil}, rows[1].Dimensions)
+	require.Equal(t, RowTypeSubtotal, rows[2].Type)
+
+	require.Equal(t, RowTypeTotal, rows[3].Type)
+	require.Equal(t, map[string]string{"geo_id": "Total", "event_type": "Total"}, rows[3].Labels)
+	require.Equal(t, ValueNumber(4), rows[3].Metrics["total"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLRepository_GroupedTotalsWithTop(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testTotalsRepository(t, DialectPostgres)
+
+	req := testTotalsRequest()
+	req.Top = &TopRequest{N: 1, Metric: "total"}
+
+	_, err := r.Grouped(req)
+	require.True(t, errors.Is(err, ErrTotalsWithTop))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
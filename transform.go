@@ -84,13 +84,28 @@ func unionRowResponse(a, b *ItemRow) {
 	}
 
 	for k := range b.Metrics {
-		//nolint:gosimple
-		if _, ok := a.Metrics[k]; ok {
+		value, ok := a.Metrics[k]
+
+		switch {
+		case !b.Metrics[k].Valid():
+			// null value does not change sum.
+			if !ok {
+				a.Metrics[k] = b.Metrics[k]
+			}
+		case ok && value.Valid():
 			a.Metrics[k] += b.Metrics[k]
-		} else {
+		default:
 			a.Metrics[k] = b.Metrics[k]
 		}
 	}
+
+	for k := range b.ExactMetrics {
+		if a.ExactMetrics == nil {
+			a.ExactMetrics = make(map[string]Decimal)
+		}
+
+		a.ExactMetrics[k] = a.ExactMetrics[k].Add(b.ExactMetrics[k])
+	}
 }
 
 func unionValueResponse(a, b *ValueResponse) {
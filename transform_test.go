@@ -17,6 +17,32 @@ func Test_UnionItemsResponse(t *testing.T) {
 		{
 			name: "empty",
 		},
+		{
+			name: "null metrics",
+			input: []*ItemsResponse{
+				{
+					Rows: []*ItemRow{{
+						Dimensions: map[string]interface{}{"geo_id": 1},
+						Metrics:    map[string]ValueNumber{"min": NullValueNumber(), "max": 5},
+					}},
+					Total: 1,
+				},
+				{
+					Rows: []*ItemRow{{
+						Dimensions: map[string]interface{}{"geo_id": 1},
+						Metrics:    map[string]ValueNumber{"min": 2, "max": NullValueNumber()},
+					}},
+					Total: 1,
+				},
+			},
+			expected: &ItemsResponse{
+				Rows: []*ItemRow{{
+					Dimensions: map[string]interface{}{"geo_id": 1},
+					Metrics:    map[string]ValueNumber{"min": 2, "max": 5},
+				}},
+				Total: 2,
+			},
+		},
 	}
 
 	for i := range tt {
@@ -0,0 +1,293 @@
+package statistica
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidWindow returns when window metric has unknown function, metric or dimensions out of groups.
+var ErrInvalidWindow = errors.New("invalid window metric")
+
+// WindowFunction special type for represent function of window metric.
+type WindowFunction string
+
+const (
+	// WindowRunningTotal cumulative sum of metric by order of dimension.
+	WindowRunningTotal WindowFunction = "running_total"
+	// WindowMovingAverage average of metric of last Size rows by order of dimension.
+	WindowMovingAverage WindowFunction = "moving_avg"
+	// WindowRank rank of row by metric in descending order, rows with equal values have same rank.
+	WindowRank WindowFunction = "rank"
+	// WindowShare share of metric of row in total of partition from 0 to 1.
+	WindowShare WindowFunction = "share"
+)
+
+// WindowMetric this struct represents requested modifier of metric computed over rows of Grouped.
+type WindowMetric struct {
+	// Name contains name of result in ItemRow.Metrics, it is metric and function joined by "_" by default.
+	Name string
+
+	// Metric contains name of metric which is modified.
+	Metric string
+
+	// Function contains window function.
+	Function WindowFunction
+
+	// PartitionBy contains groups which values split rows into independent windows.
+	PartitionBy []string
+
+	// OrderBy contains group which orders rows of running total and moving average.
+	OrderBy string
+
+	// Size contains count of rows of moving average.
+	Size int
+}
+
+func (w *WindowMetric) name() string {
+	if w.Name != "" {
+		return w.Name
+	}
+
+	return w.Metric + "_" + string(w.Function)
+}
+
+func (w *WindowMetric) ordered() bool {
+	return w.Function == WindowRunningTotal || w.Function == WindowMovingAverage
+}
+
+// windows returns true if dialect supports window functions over aggregates.
+func (d Dialect) windows() bool {
+	return d != DialectGeneric
+}
+
+// checkWindows returns ErrInvalidWindow if window metric of request could not be computed.
+func (r *SQLRepository) checkWindows(req *ItemsRequest) error {
+	for _, w := range req.Windows {
+		switch {
+		case r.getMetric(w.Metric) == nil:
+			return fmt.Errorf("%w: unknown metric %q", ErrInvalidWindow, w.Metric)
+		case w.Function != WindowRunningTotal && w.Function != WindowMovingAverage &&
+			w.Function != WindowRank && w.Function != WindowShare:
+			return fmt.Errorf("%w: unknown function %q", ErrInvalidWindow, w.Function)
+		case w.ordered() && !inStrings(req.Groups, w.OrderBy):
+			return fmt.Errorf("%w: order by %q is not in groups", ErrInvalidWindow, w.OrderBy)
+		case w.Function == WindowMovingAverage && w.Size <= 0:
+			return fmt.Errorf("%w: size %d of moving average", ErrInvalidWindow, w.Size)
+		}
+
+		for _, key := range w.PartitionBy {
+			if !inStrings(req.Groups, key) {
+				return fmt.Errorf("%w: partition by %q is not in groups", ErrInvalidWindow, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// pushdownWindow returns true if window metric is computed by database.
+func (r *SQLRepository) pushdownWindow(w *WindowMetric) bool {
+	if !r.dialect.windows() {
+		return false
+	}
+
+	_, ok := r.dialect.metricExpression(r.getMetric(w.Metric))
+
+	return ok
+}
+
+// windowExpression returns sql expression of window metric over aggregated expression of metric.
+func (r *SQLRepository) windowExpression(w *WindowMetric) string {
+	expression := r.metricExpression(r.getMetric(w.Metric))
+
+	partition := make([]string, 0, len(w.PartitionBy))
+
+	for _, key := range w.PartitionBy {
+		dim, _ := r.getDimension(DimensionKey(key))
+		partition = append(partition, dim.Expression)
+	}
+
+	over := ""
+	if len(partition) > 0 {
+		over = "PARTITION BY " + strings.Join(partition, ",")
+	}
+
+	if w.ordered() {
+		dim, _ := r.getDimension(DimensionKey(w.OrderBy))
+		over = strings.TrimSpace(over + " ORDER BY " + dim.Expression)
+	}
+
+	switch w.Function {
+	case WindowRunningTotal:
+		return fmt.Sprintf("sum(%s) OVER (%s ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)", expression, over)
+	case WindowMovingAverage:
+		return fmt.Sprintf("avg(%s) OVER (%s ROWS BETWEEN %d PRECEDING AND CURRENT ROW)", expression, over, w.Size-1)
+	case WindowRank:
+		return fmt.Sprintf("rank() OVER (%s)", strings.TrimSpace(over+" ORDER BY "+expression+" DESC"))
+	case WindowShare:
+		// multiplication by 1.0 avoids integer division of counts.
+		return fmt.Sprintf("%s * 1.0 / NULLIF(sum(%s) OVER (%s), 0)", expression, expression, over)
+	}
+
+	return "NULL"
+}
+
+// applySelectWindows adds window metrics which are computed by database to select.
+func (r *SQLRepository) applySelectWindows(req *ItemsRequest, query *string) {
+	for _, w := range req.Windows {
+		if r.pushdownWindow(w) {
+			*query += "," + r.windowExpression(w) + " AS " + w.name()
+		}
+	}
+}
+
+// getWindow returns window metric of request by name.
+func getWindow(req *ItemsRequest, name string) *WindowMetric {
+	for _, w := range req.Windows {
+		if w.name() == name {
+			return w
+		}
+	}
+
+	return nil
+}
+
+// applyWindowMetrics computes window metrics which are not computed by database in Go.
+// Unlike database, values are computed over returned rows only.
+func (r *SQLRepository) applyWindowMetrics(req *ItemsRequest, response []*ItemRow) {
+	for _, w := range req.Windows {
+		if r.pushdownWindow(w) {
+			continue
+		}
+
+		partitions := make(map[string][]*ItemRow)
+		keys := make([]string, 0)
+
+		for _, row := range response {
+			values := make([]interface{}, 0, len(w.PartitionBy))
+			for _, key := range w.PartitionBy {
+				values = append(values, row.Dimensions[key])
+			}
+
+			key := fallbackKey(values)
+			if _, ok := partitions[key]; !ok {
+				keys = append(keys, key)
+			}
+
+			partitions[key] = append(partitions[key], row)
+		}
+
+		for _, key := range keys {
+			applyWindow(w, partitions[key])
+		}
+	}
+}
+
+func applyWindow(w *WindowMetric, rows []*ItemRow) {
+	name := w.name()
+	ordered := append(make([]*ItemRow, 0, len(rows)), rows...)
+
+	switch w.Function {
+	case WindowRunningTotal, WindowMovingAverage:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return compareValues(ordered[i].Dimensions[w.OrderBy], ordered[j].Dimensions[w.OrderBy]) < 0
+		})
+
+		sum := ValueNumber(0)
+
+		for i, row := range ordered {
+			sum += validValue(row.Metrics[w.Metric])
+
+			if w.Function == WindowRunningTotal {
+				row.Metrics[name] = sum
+
+				continue
+			}
+
+			from := i - w.Size + 1
+			if from > 0 {
+				sum -= validValue(ordered[from-1].Metrics[w.Metric])
+			} else {
+				from = 0
+			}
+
+			row.Metrics[name] = sum / ValueNumber(i-from+1)
+		}
+
+	case WindowRank:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return validValue(ordered[i].Metrics[w.Metric]) > validValue(ordered[j].Metrics[w.Metric])
+		})
+
+		for i, row := range ordered {
+			rank := ValueNumber(i + 1)
+			if i > 0 && validValue(ordered[i-1].Metrics[w.Metric]) == validValue(row.Metrics[w.Metric]) {
+				rank = ordered[i-1].Metrics[name]
+			}
+
+			row.Metrics[name] = rank
+		}
+
+	case WindowShare:
+		sum := ValueNumber(0)
+		for _, row := range rows {
+			sum += validValue(row.Metrics[w.Metric])
+		}
+
+		for _, row := range rows {
+			row.Metrics[name] = NullValueNumber()
+			if sum != 0 && row.Metrics[w.Metric].Valid() {
+				row.Metrics[name] = row.Metrics[w.Metric] / sum
+			}
+		}
+	}
+}
+
+// validValue returns value or zero for NULL value.
+func validValue(v ValueNumber) ValueNumber {
+	if !v.Valid() {
+		return 0
+	}
+
+	return v
+}
+
+// compareValues compares values of dimension, NULL is less than any value.
+func compareValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Compare(tb)
+		}
+	}
+
+	if fa, err := toFloat64(a); err == nil {
+		if fb, err := toFloat64(b); err == nil {
+			return compareFloat64(fa, fb)
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,123 @@
+package statistica
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func testWindowRepository(t *testing.T, dialect Dialect) (*SQLRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return NewSQLRepository(db, testTable,
+		[]*Dimension{
+			{Name: "geo_id", Expression: "geo_id"},
+			{Name: "created", Expression: "date(created)", Type: DimensionTypeDate},
+		},
+		[]*Metric{{Name: "cost", Expression: "sum(price)"}},
+		DialectSQLRepositoryOption(dialect),
+	), mock
+}
+
+func TestSQLRepository_WindowsPushdown(t *testing.T) {
+	t.Parallel()
+
+	r, _ := testWindowRepository(t, DialectPostgres)
+
+	plan, err := r.Explain(MethodGrouped, &ItemsRequest{
+		Groups: []string{"geo_id", "created"},
+		SortBy: []*ItemsRequestOrder{{Key: "cost_rank", Direction: "ASC"}},
+		Windows: []*WindowMetric{
+			{Metric: "cost", Function: WindowRunningTotal, PartitionBy: []string{"geo_id"}, OrderBy: "created"},
+			{Metric: "cost", Function: WindowMovingAverage, OrderBy: "created", Size: 7, Name: "cost_7d"},
+			{Metric: "cost", Function: WindowRank, PartitionBy: []string{"created"}},
+			{Metric: "cost", Function: WindowShare},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t,
+		"SELECT geo_id,date(created), sum(price) AS cost,"+
+			"sum(sum(price)) OVER (PARTITION BY geo_id ORDER BY date(created) "+
+			"ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS cost_running_total,"+
+			"avg(sum(price)) OVER (ORDER BY date(created) ROWS BETWEEN 6 PRECEDING AND CURRENT ROW) AS cost_7d,"+
+			"rank() OVER (PARTITION BY date(created) ORDER BY sum(price) DESC) AS cost_rank,"+
+			"sum(price) * 1.0 / NULLIF(sum(sum(price)) OVER (), 0) AS cost_share "+
+			"FROM test_table  GROUP BY  geo_id,date(created) ORDER BY cost_rank ASC",
+		plan.Query,
+	)
+}
+
+func TestSQLRepository_WindowsInvalid(t *testing.T) {
+	t.Parallel()
+
+	r, _ := testWindowRepository(t, DialectPostgres)
+
+	tt := []struct {
+		name   string
+		window *WindowMetric
+	}{
+		{name: "unknown metric", window: &WindowMetric{Metric: "unknown", Function: WindowShare}},
+		{name: "unknown function", window: &WindowMetric{Metric: "cost", Function: "lag"}},
+		{name: "order out of groups", window: &WindowMetric{Metric: "cost", Function: WindowRunningTotal, OrderBy: "created"}},
+		{name: "partition out of groups", window: &WindowMetric{Metric: "cost", Function: WindowRank, PartitionBy: []string{"created"}}},
+		{name: "moving average size", window: &WindowMetric{Metric: "cost", Function: WindowMovingAverage, OrderBy: "geo_id"}},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := r.Grouped(&ItemsRequest{Groups: []string{"geo_id"}, Windows: []*WindowMetric{tc.window}})
+			require.True(t, errors.Is(err, ErrInvalidWindow))
+		})
+	}
+}
+
+func TestSQLRepository_WindowsInGo(t *testing.T) {
+	t.Parallel()
+
+	r, mock := testWindowRepository(t, DialectGeneric)
+
+	day := func(d int) time.Time {
+		return time.Date(2022, 10, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	mock.ExpectQuery("^SELECT geo_id,date\\(created\\), sum\\(price\\) AS cost FROM test_table GROUP BY geo_id,date\\(created\\)$").
+		WillReturnRows(sqlmock.NewRows([]string{"geo_id", "created", "cost"}).
+			AddRow(int64(1), day(3), int64(30)).
+			AddRow(int64(1), day(1), int64(10)).
+			AddRow(int64(2), day(1), int64(40)).
+			AddRow(int64(1), day(2), int64(20)))
+
+	rows, err := r.Grouped(&ItemsRequest{
+		Groups: []string{"geo_id", "created"},
+		Windows: []*WindowMetric{
+			{Metric: "cost", Function: WindowRunningTotal, PartitionBy: []string{"geo_id"}, OrderBy: "created"},
+			{Metric: "cost", Function: WindowMovingAverage, PartitionBy: []string{"geo_id"}, OrderBy: "created", Size: 2},
+			{Metric: "cost", Function: WindowRank},
+			{Metric: "cost", Function: WindowShare, PartitionBy: []string{"geo_id"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	require.Equal(t, map[string]ValueNumber{
+		"cost": 30, "cost_running_total": 60, "cost_moving_avg": 25, "cost_rank": 2, "cost_share": 0.5,
+	}, rows[0].Metrics)
+	require.Equal(t, map[string]ValueNumber{
+		"cost": 10, "cost_running_total": 10, "cost_moving_avg": 10, "cost_rank": 4, "cost_share": ValueNumber(10) / 60,
+	}, rows[1].Metrics)
+	require.Equal(t, map[string]ValueNumber{
+		"cost": 40, "cost_running_total": 40, "cost_moving_avg": 40, "cost_rank": 1, "cost_share": 1,
+	}, rows[2].Metrics)
+	require.Equal(t, ValueNumber(15), rows[3].Metrics["cost_moving_avg"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}